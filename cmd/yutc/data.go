@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+
+	yutc "github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newDataCommand(settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) *cobra.Command {
+	dataCommand := &cobra.Command{
+		Use:   "data [flags]",
+		Short: "Merge data inputs and print the result",
+		Long: "Merge data inputs (including --set values and schema defaults) and output the result " +
+			"without rendering any templates.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDataDump(cmd.Context(), settings, runData, logger)
+		},
+		SilenceUsage: true,
+	}
+	return dataCommand
+}
+
+func initDataCommand(dataCommand *cobra.Command, runSettings *types.Arguments, systemGroup *pflag.FlagSet) {
+	dataCommand.Flags().SortFlags = false
+
+	dataGroup := pflag.NewFlagSet("Data", pflag.ContinueOnError)
+	outputGroup := pflag.NewFlagSet("Output", pflag.ContinueOnError)
+
+	dataGroup.StringArrayVarP(
+		&runSettings.DataFiles,
+		"data",
+		"d",
+		nil,
+		"Data file to parse and merge. Can be a file or a URL. "+
+			"Can be specified multiple times and the inputs will be merged. "+
+			"See --help=syntax for more details.",
+	)
+	dataGroup.StringArrayVarP(
+		&runSettings.SetData,
+		"set",
+		"",
+		nil,
+		"Set a data value via a key path (path=value), parsing the value as JSON if possible. "+
+			"Append to an array with path+=value or path[-]=value. Can be specified multiple times.",
+	)
+	dataGroup.StringArrayVar(&runSettings.SetString, "set-string", nil, "Like --set, but the value is always a string")
+	dataGroup.StringArrayVar(&runSettings.SetFile, "set-file", nil, "Like --set, but the value is the contents of a file (path=./file)")
+	dataGroup.StringArrayVar(&runSettings.SetJSON, "set-json", nil, "Like --set, but the value must be valid JSON")
+	dataGroup.StringArrayVar(&runSettings.Unset, "unset", nil, "Remove the value at a key path after all other --set flags. Can be specified multiple times.")
+	dataGroup.BoolVar(&runSettings.ResolveRefs, "resolve-refs", false, "Expand ${.path.to.value} and ${env:VAR} references in string values of the merged data")
+	dataGroup.BoolVar(&runSettings.Prompt, "prompt", false, "Ask on the terminal for required values that are missing from the data, using the kind=schema inputs")
+	dataGroup.BoolVar(&runSettings.Helm, "helm", false, "Enable Helm-specific data processing (Convert keys specified with key=Chart to pascalcase)")
+	dataGroup.StringVar(&runSettings.Auth, "auth", "", "Authentication for any URL source. Format: 'user:pass' for Basic Auth or 'token' for Bearer Token.")
+
+	outputGroup.StringVarP(&runSettings.DataFormat, "format", "f", "yaml", "Output format, one of: yaml, json, toml")
+	outputGroup.StringVarP(&runSettings.DataJSONPath, "jsonpath", "j", "", "Only output the data selected by this JSONPath (ex: .app.name)")
+	outputGroup.StringVarP(&runSettings.Output, "output", "o", "-", "Output file, defaults to stdout")
+	outputGroup.BoolVarP(&runSettings.Overwrite, "overwrite", "w", false, "Overwrite existing files")
+
+	dataCommand.Flags().AddFlagSet(dataGroup)
+	dataCommand.Flags().AddFlagSet(outputGroup)
+
+	ConfigureHelp(dataCommand, []*pflag.FlagSet{dataGroup, outputGroup, systemGroup})
+}
+
+func runDataDump(ctx context.Context, settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) error {
+	app := yutc.NewApp(settings, runData, logger)
+	return app.DumpData(ctx)
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDataCommand(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "Data merged to yaml",
+		Args: func(_ string) []string {
+			return []string{
+				"data",
+				"-d", "../../testFiles/data/data1.yaml",
+				"-d", "../../testFiles/data/data2.yaml",
+				"--set", ".thisIsNew=1001",
+			}
+		},
+		ExpectedStdout: "ditto:\n- woohooo\n- yipeee\ndogs: []\nthisIsNew: 1001\nthisWillMerge:\n  value23: 23\n  value24: 24\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data queried to json",
+		Args: func(_ string) []string {
+			return []string{
+				"data",
+				"-d", "../../testFiles/data/data1.yaml",
+				"-d", "../../testFiles/data/data2.yaml",
+				"--format", "json",
+				"--jsonpath", ".thisWillMerge",
+			}
+		},
+		ExpectedStdout: "{\n  \"value23\": 23,\n  \"value24\": 24\n}\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data with schema defaults to toml file",
+		InputFiles: map[string]string{
+			"data.yaml":   "name: test\n",
+			"schema.json": `{"type": "object", "properties": {"port": {"type": "integer", "default": 8080}}}`,
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "data.yaml"),
+				"-d", "kind=schema,src=" + filepath.Join(rootDir, "schema.json"),
+				"-f", "toml",
+				"-o", filepath.Join(rootDir, "out.toml"),
+			}
+		},
+		ExpectedFiles: map[string]string{
+			"out.toml": "name = 'test'\nport = 8080\n",
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data writes whole-number floats as integers",
+		Args: func(_ string) []string {
+			return []string{"data", "--set-json", `.replicas=3`, "--set", ".ratio=0.5"}
+		},
+		ExpectedStdout: "ratio: 0.5\nreplicas: 3\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data output exists",
+		InputFiles: map[string]string{
+			"data.yaml": "name: test\n",
+			"out.yaml":  "existing content",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "data.yaml"),
+				"-o", filepath.Join(rootDir, "out.yaml"),
+			}
+		},
+		ExpectedError: "exists and `overwrite` is not set",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data list nested under jsonpath",
+		InputFiles: map[string]string{
+			"items.json": `[{"name": "a"}, {"name": "b"}]`,
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", "jsonpath=.items,src=" + filepath.Join(rootDir, "items.json"),
+				"-f", "json",
+				"-j", ".items[1].name",
+			}
+		},
+		ExpectedStdout: "\"b\"\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data list at root",
+		InputFiles: map[string]string{
+			"items.json": `[{"name": "a"}]`,
+		},
+		Args: func(rootDir string) []string {
+			return []string{"data", "-d", filepath.Join(rootDir, "items.json")}
+		},
+		ExpectedError: "only objects can be merged at the root but the json data is a list, use jsonpath= to nest it",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data multi-document yaml",
+		InputFiles: map[string]string{
+			"all.yaml": "kind: Service\nname: web\n---\nkind: Deployment\nname: web\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", "jsonpath=.manifests,src=" + filepath.Join(rootDir, "all.yaml") + ",kind=data(documents=list)",
+				"-d", "jsonpath=.merged,src=" + filepath.Join(rootDir, "all.yaml") + ",kind=data(documents=merge)",
+				"-f", "json",
+				"-j", "$['merged']['kind']",
+			}
+		},
+		ExpectedStdout: "\"Deployment\"\n",
+	})
+
+	t.Setenv("YUTC_TEST_DB__HOST", "db.local")
+	t.Setenv("YUTC_TEST_DB__PORT", "5433")
+	runTest(t, &TestCase{
+		Name: "Data from environment variables",
+		InputFiles: map[string]string{
+			"data.yaml":   "db:\n  host: localhost\n  port: 5432\n  name: app\n",
+			"schema.json": `{"type": "object", "properties": {"db": {"type": "object", "properties": {"port": {"type": "integer"}}}}}`,
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "data.yaml"),
+				"-d", "src=env,type=env(prefix=YUTC_TEST_,separator=__)",
+				"-d", "kind=schema,src=" + filepath.Join(rootDir, "schema.json"),
+			}
+		},
+		ExpectedStdout: "db:\n  host: db.local\n  name: app\n  port: 5433\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data from sops secret",
+		Args: func(_ string) []string {
+			return []string{
+				"data",
+				"-d", "jsonpath=.secrets,src=../../testFiles/secrets/secrets.sops.json," +
+					"kind=secret(keyfile=../../testFiles/secrets/age-test-key.txt)",
+				"-f", "json",
+			}
+		},
+		ExpectedStdout: "{\n  \"secrets\": {\n    \"db\": {\n      \"password\": \"s3cret\",\n      \"user\": \"admin\"\n    },\n" +
+			"    \"hosts\": [\n      [\n        \"a\",\n        \"b\"\n      ],\n      [\n        {\n          \"port\": 5432\n        }\n      ]\n    ],\n" +
+			"    \"retries\": 3\n  }\n}\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data with resolved references",
+		InputFiles: map[string]string{
+			"base.yaml": "domain: example.com\nurl: https://api.${.domain}\n",
+			"prod.yaml": "domain: example.org\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "base.yaml"),
+				"-d", filepath.Join(rootDir, "prod.yaml"),
+				"--resolve-refs",
+			}
+		},
+		ExpectedStdout: "domain: example.org\nurl: https://api.example.org\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data references are left alone without --resolve-refs",
+		InputFiles: map[string]string{
+			"base.yaml": "url: https://api.${.domain}\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{"data", "-d", filepath.Join(rootDir, "base.yaml")}
+		},
+		ExpectedStdout: "url: https://api.${.domain}\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data template renders against previously merged data",
+		InputFiles: map[string]string{
+			"base.yaml":         "name: api\n",
+			"derived.yaml.tmpl": "fullname: {{ .name }}-svc\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "base.yaml"),
+				"-d", "src=" + filepath.Join(rootDir, "derived.yaml.tmpl") + ",kind=data(template=true)",
+			}
+		},
+		ExpectedStdout: "fullname: api-svc\nname: api\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data set flags are applied in order",
+		InputFiles: map[string]string{
+			"base.yaml": "items: [a]\nremove: me\n",
+			"cert.pem":  "CERT",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "base.yaml"),
+				"--unset", ".remove",
+				"--set-string", ".version=1.10",
+				"--set", ".items[-]=b",
+				"--set-json", `.items=["x"]`,
+				"--set-file", ".cert=" + filepath.Join(rootDir, "cert.pem"),
+			}
+		},
+		ExpectedStdout: "cert: CERT\nitems:\n- x\n- b\nversion: \"1.10\"\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data set with wildcard and filter paths",
+		InputFiles: map[string]string{
+			"base.yaml": "services:\n- tier: web\n- tier: db\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "base.yaml"),
+				"--set", "$.services[*].replicas=2",
+				"--set", `$.services[?@.tier=="web"].image=nginx`,
+			}
+		},
+		ExpectedStdout: "services:\n- image: nginx\n  replicas: 2\n  tier: web\n- replicas: 2\n  tier: db\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data schema errors are all reported with their source",
+		InputFiles: map[string]string{
+			"schema.yaml": "type: object\nproperties:\n  db:\n    $ref: db.yaml\n  name:\n    type: string\n",
+			"db.yaml":     "type: object\nproperties:\n  port:\n    type: integer\n",
+			"values.yaml": "name: 1\ndb:\n  port: x\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"-d", filepath.Join(rootDir, "values.yaml"),
+				"-d", "kind=schema,src=" + filepath.Join(rootDir, "schema.yaml"),
+			}
+		},
+		ExpectedError: "2 error(s)\n  /db/port: got string, want integer (from ",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data prompt without a terminal lists missing values",
+		InputFiles: map[string]string{
+			"schema.yaml": "type: object\nrequired: [name, port]\nproperties:\n  name:\n    description: Project name\n  port:\n    type: integer\n",
+			"values.yaml": "port: 80\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{
+				"data",
+				"--prompt",
+				"-d", filepath.Join(rootDir, "values.yaml"),
+				"-d", "kind=schema,src=" + filepath.Join(rootDir, "schema.yaml"),
+			}
+		},
+		ExpectedError: "stdin is not a terminal\n  /name: Project name",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data invalid format",
+		Args: func(_ string) []string {
+			return []string{"data", "-d", "../../testFiles/data/data1.yaml", "-f", "docx"}
+		},
+		ExpectedError: "invalid format: docx",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data input only format",
+		Args: func(_ string) []string {
+			return []string{"data", "-d", "does-not-exist.yaml", "-f", "csv"}
+		},
+		ExpectedError: "unsupported output format: csv, must be one of: yaml, json, toml",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Data scalar as toml",
+		Args: func(_ string) []string {
+			return []string{
+				"data",
+				"-d", "../../testFiles/data/data2.yaml",
+				"-f", "toml",
+				"-j", ".thisWillMerge.value23",
+			}
+		},
+		ExpectedError: "unable to encode data as toml: a TOML document must be a map, got",
+	})
+}
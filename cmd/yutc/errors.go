@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adam-huganir/yutc/pkg/types"
+)
+
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+// jsonError is an error printed with --error-format json.
+type jsonError struct {
+	Error    string               `json:"error"`
+	Template *types.TemplateError `json:"template,omitempty"` // details of template errors
+}
+
+func validateErrorFormat(format string) error {
+	switch format {
+	case errorFormatText, errorFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid error format %q: must be one of: %s, %s", format, errorFormatText, errorFormatJSON)
+}
+
+// printError writes err to w in the format set by --error-format. Template errors are printed with their
+// source and context, highlighted with color when enabled.
+func printError(w io.Writer, err error, format string, color bool) {
+	var templateErr *types.TemplateError
+	isTemplateErr := errors.As(err, &templateErr)
+	if format == errorFormatJSON {
+		out := jsonError{Error: err.Error()}
+		if isTemplateErr {
+			out.Template = templateErr
+		}
+		b, marshalErr := json.Marshal(out)
+		if marshalErr == nil {
+			_, _ = fmt.Fprintln(w, string(b))
+			return
+		}
+	}
+	if isTemplateErr {
+		_, _ = fmt.Fprintln(w, "Error: "+templateErr.Pretty(color))
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Error:", err.Error())
+}
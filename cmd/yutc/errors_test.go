@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintError(t *testing.T) {
+	templateErr := &types.TemplateError{
+		TemplatePath: "values.yaml",
+		Line:         2,
+		Column:       8,
+		Expression:   ".image.tag",
+		DataPath:     ".Values.image.tag",
+		Hint:         ".Values.image is nil",
+		Message:      "nil pointer evaluating interface {}.tag",
+		Snippet:      []types.SourceLine{{Number: 1, Text: "a: 1"}, {Number: 2, Text: "tag: {{ .image.tag }}"}},
+		Err:          errors.New("template: values.yaml:2:7: nil pointer evaluating interface {}.tag"),
+	}
+	wrapped := fmt.Errorf("unable to render: %w", templateErr)
+
+	t.Run("text", func(t *testing.T) {
+		var b bytes.Buffer
+		printError(&b, wrapped, errorFormatText, false)
+		assert.Contains(t, b.String(), "Error: values.yaml:2:8: nil pointer evaluating interface {}.tag")
+		assert.Contains(t, b.String(), "tag: {{ .image.tag }}")
+		assert.Contains(t, b.String(), ".Values.image is nil")
+		assert.NotContains(t, b.String(), "\x1b[")
+	})
+
+	t.Run("text color", func(t *testing.T) {
+		var b bytes.Buffer
+		printError(&b, wrapped, errorFormatText, true)
+		assert.Contains(t, b.String(), "\x1b[")
+	})
+
+	t.Run("text caret after multi-byte characters", func(t *testing.T) {
+		var b bytes.Buffer
+		printError(&b, &types.TemplateError{
+			TemplatePath: "values.yaml",
+			Line:         1,
+			Column:       9, // byte column of {{, after the 2 byte é
+			Message:      "boom",
+			Snippet:      []types.SourceLine{{Number: 1, Text: "\tcafé: {{ .x }}"}},
+			Err:          errors.New("boom"),
+		}, errorFormatText, false)
+		assert.Contains(t, b.String(), "  > 1 | \tcafé: {{ .x }}\n      | \t      ^\n")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var b bytes.Buffer
+		printError(&b, wrapped, errorFormatJSON, false)
+		var out map[string]any
+		require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+		assert.Equal(t, wrapped.Error(), out["error"])
+		template := out["template"].(map[string]any)
+		assert.Equal(t, "values.yaml", template["template"])
+		assert.Equal(t, float64(2), template["line"])
+		assert.Equal(t, ".Values.image.tag", template["dataPath"])
+	})
+
+	t.Run("json other error", func(t *testing.T) {
+		var b bytes.Buffer
+		printError(&b, errors.New("boom"), errorFormatJSON, false)
+		assert.JSONEq(t, `{"error": "boom"}`, b.String())
+	})
+}
+
+func TestInvalidErrorFormat(t *testing.T) {
+	runTest(t, &TestCase{
+		Name:          "invalid error format",
+		InputFiles:    map[string]string{"a.tmpl": "a"},
+		Args:          func(rootDir string) []string { return []string{"--error-format", "yaml", rootDir + "/a.tmpl"} },
+		ExpectedError: `invalid error format "yaml"`,
+	})
+}
@@ -80,6 +80,20 @@ func TestUvPythonExample(t *testing.T) {
 	})
 }
 
+func TestSimpleHelmExampleTests(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "Test simple helm example against its build",
+		Args: func(rootDir string) []string {
+			return []string{"test", "-o", path.Join(rootDir, "report.txt"), "../../examples/simple-helm/tests"}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(path.Join(rootDir, "report.txt"))
+			assert.NoError(t, err)
+			assert.Contains(t, string(report), "6 tests, 6 passed, 0 failed, 0 errors\n")
+		},
+	})
+}
+
 func tailMergeDir(buildDir string) (string, error) {
 	var f []string
 	err := fs.WalkDir(os.DirFS("../.."), strings.TrimPrefix(buildDir, "../../"), func(fpath string, d fs.DirEntry, err error) error {
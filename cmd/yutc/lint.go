@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+
+	yutc "github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newLintCommand(settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) *cobra.Command {
+	lintCommand := &cobra.Command{
+		Use:   "lint [flags] <templates...>",
+		Short: "Check templates for problems without rendering them",
+		Long: "Parse templates without rendering them and report calls to templates that are never defined, " +
+			"define blocks that are never used, data keys that are neither in the data nor a schema (when data is " +
+			"given), use of shell without --allow-shell, and whitespace trimming that joins output to the text " +
+			"around it. Exits with status 1 if any problem is an error.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(cmd.Context(), settings, runData, logger, args)
+		},
+		SilenceUsage: true,
+	}
+	return lintCommand
+}
+
+func initLintCommand(lintCommand *cobra.Command, runSettings *types.Arguments, systemGroup *pflag.FlagSet) {
+	lintCommand.Flags().SortFlags = false
+
+	dataTemplateGroup := pflag.NewFlagSet("Data & Templates", pflag.ContinueOnError)
+	outputGroup := pflag.NewFlagSet("Output", pflag.ContinueOnError)
+
+	dataTemplateGroup.StringArrayVarP(
+		&runSettings.DataFiles,
+		"data",
+		"d",
+		nil,
+		"Data file to check the data keys used by templates against, with any kind=schema inputs. "+
+			"Can be specified multiple times and the inputs will be merged. See --help=syntax for more details.",
+	)
+	dataTemplateGroup.StringArrayVarP(&runSettings.SetData, "set", "", nil, "Set a data value via a key path (path=value), as when rendering")
+	dataTemplateGroup.StringArrayVar(&runSettings.SetString, "set-string", nil, "Like --set, but the value is always a string")
+	dataTemplateGroup.StringArrayVar(&runSettings.SetFile, "set-file", nil, "Like --set, but the value is the contents of a file (path=./file)")
+	dataTemplateGroup.StringArrayVar(&runSettings.SetJSON, "set-json", nil, "Like --set, but the value must be valid JSON")
+	dataTemplateGroup.BoolVar(&runSettings.Helm, "helm", false, "Enable Helm-specific data processing (Convert keys specified with key=Chart to pascalcase)")
+	dataTemplateGroup.StringArrayVarP(
+		&runSettings.CommonTemplateFiles,
+		"common-templates",
+		"c",
+		nil,
+		"Templates to be shared across all arguments in template list. Can be a file or a URL. Can be specified multiple times.",
+	)
+	dataTemplateGroup.BoolVar(&runSettings.AllowShell, "allow-shell", false, "Allow templates to use the 'shell' template function")
+	dataTemplateGroup.StringVar(&runSettings.Auth, "auth", "", "Authentication for any URL source. Format: 'user:pass' for Basic Auth or 'token' for Bearer Token.")
+	dataTemplateGroup.StringVar(&runSettings.DropExtension, "drop-extension", "tmpl", "Drop file extension from template names, as when rendering")
+
+	outputGroup.StringVarP(&runSettings.LintFormat, "format", "f", "text", "Report format, one of: text, sarif")
+	outputGroup.StringVarP(&runSettings.Output, "output", "o", "-", "Output file, defaults to stdout")
+	outputGroup.BoolVarP(&runSettings.Overwrite, "overwrite", "w", false, "Overwrite existing files")
+
+	lintCommand.Flags().AddFlagSet(dataTemplateGroup)
+	lintCommand.Flags().AddFlagSet(outputGroup)
+
+	ConfigureHelp(lintCommand, []*pflag.FlagSet{dataTemplateGroup, outputGroup, systemGroup})
+}
+
+func runLint(ctx context.Context, settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger, args []string) error {
+	app := yutc.NewApp(settings, runData, logger)
+	return app.Lint(ctx, args)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCommand(t *testing.T) {
+	inputFiles := map[string]string{
+		"_helpers.tmpl":    "{{ define \"name\" }}{{ .name }}{{ end }}\n{{ define \"unused\" }}{{ end }}\n",
+		"app.yaml.tmpl":    "name: {{ include \"name\" .app }}\nport: {{ .app.port }}\n",
+		"values.yaml":      "app:\n  name: demo\n",
+		"schema.yaml":      "type: object\nproperties:\n  app:\n    type: object\n    properties:\n      port: {type: integer}\n",
+		"undefined.tmpl":   "{{ template \"nope\" . }}\n",
+		"shell.tmpl":       "{{ shell \"echo hi\" }}\n",
+		"unparsable.tmpl":  "{{ .a | nope }}\n",
+		"whitespace.tmpl":  "a: {{- .a }}  \n",
+		"withoutData.tmpl": "{{ .anything.goes }}\n",
+	}
+
+	runTest(t, &TestCase{
+		Name:       "Lint reports warnings without failing",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"lint", "-c", filepath.Join(rootDir, "_helpers.tmpl"), "-d", filepath.Join(rootDir, "values.yaml"),
+				"-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, "app.yaml.tmpl")}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+			require.NoError(t, err)
+			assert.Equal(t,
+				filepath.Join(rootDir, "_helpers.tmpl")+":2:1: warning: template \"unused\" is defined but never used (unused-define)\n"+
+					filepath.Join(rootDir, "app.yaml.tmpl")+":2:10: warning: .app.port is not in the data or a schema (missing-data)\n",
+				string(report))
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name:       "Lint checks data keys against schemas",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"lint", "-c", filepath.Join(rootDir, "_helpers.tmpl"), "-d", filepath.Join(rootDir, "values.yaml"),
+				"-d", "kind=schema,src=" + filepath.Join(rootDir, "schema.yaml"),
+				"-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, "app.yaml.tmpl")}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+			require.NoError(t, err)
+			assert.NotContains(t, string(report), "missing-data")
+		},
+	})
+
+	for _, tc := range []struct {
+		file, expected string
+	}{
+		{"undefined.tmpl", `undefined.tmpl:1:13: error: template "nope" is not defined (undefined-template)`},
+		{"shell.tmpl", "shell.tmpl:1:4: error: shell is only available with --allow-shell (shell-not-allowed)"},
+		{"unparsable.tmpl", `unparsable.tmpl:1: error: function "nope" not defined (parse-error)`},
+	} {
+		runTest(t, &TestCase{
+			Name:       "Lint reports " + tc.file,
+			InputFiles: inputFiles,
+			Args: func(rootDir string) []string {
+				return []string{"lint", "-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, tc.file)}
+			},
+			ExpectedError: "lint found 1 problem(s)",
+			Verify: func(t *testing.T, rootDir string) {
+				report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+				require.NoError(t, err)
+				assert.Equal(t, filepath.Join(rootDir, tc.expected)+"\n", string(report))
+			},
+		})
+	}
+
+	runTest(t, &TestCase{
+		Name:       "Lint allows shell with --allow-shell and skips data keys without data",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"lint", "--allow-shell", "-o", filepath.Join(rootDir, "report.txt"),
+				filepath.Join(rootDir, "shell.tmpl"), filepath.Join(rootDir, "withoutData.tmpl")}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+			require.NoError(t, err)
+			assert.Empty(t, string(report))
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name:       "Lint writes SARIF",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"lint", "-f", "sarif", "-o", filepath.Join(rootDir, "report.sarif"), filepath.Join(rootDir, "whitespace.tmpl")}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.sarif"))
+			require.NoError(t, err)
+			var sarif struct {
+				Version string `json:"version"`
+				Runs    []struct {
+					Results []struct {
+						RuleID string `json:"ruleId"`
+						Level  string `json:"level"`
+					} `json:"results"`
+				} `json:"runs"`
+			}
+			require.NoError(t, json.Unmarshal(report, &sarif))
+			assert.Equal(t, "2.1.0", sarif.Version)
+			require.Len(t, sarif.Runs, 1)
+			var rules []string
+			for _, result := range sarif.Runs[0].Results {
+				rules = append(rules, result.RuleID+":"+result.Level)
+			}
+			assert.Equal(t, []string{"trim-joins-text:warning", "trailing-whitespace:note"}, rules)
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name:       "Lint rejects unknown formats",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"lint", "-f", "xml", filepath.Join(rootDir, "app.yaml.tmpl")}
+		},
+		ExpectedError: `invalid lint format "xml"`,
+	})
+}
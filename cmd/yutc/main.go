@@ -12,6 +12,7 @@ import (
 	"github.com/adam-huganir/yutc/pkg/config"
 	"github.com/adam-huganir/yutc/pkg/logging"
 	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -48,8 +49,15 @@ func initRoot(rootCommand *cobra.Command, runSettings *types.Arguments) {
 		"set",
 		"",
 		nil,
-		"Set a data value via a key path. Can be specified multiple times.",
+		"Set a data value via a key path (path=value), parsing the value as JSON if possible. "+
+			"Append to an array with path+=value or path[-]=value. Can be specified multiple times.",
 	)
+	dataTemplateGroup.StringArrayVar(&runSettings.SetString, "set-string", nil, "Like --set, but the value is always a string")
+	dataTemplateGroup.StringArrayVar(&runSettings.SetFile, "set-file", nil, "Like --set, but the value is the contents of a file (path=./file)")
+	dataTemplateGroup.StringArrayVar(&runSettings.SetJSON, "set-json", nil, "Like --set, but the value must be valid JSON")
+	dataTemplateGroup.StringArrayVar(&runSettings.Unset, "unset", nil, "Remove the value at a key path after all other --set flags. Can be specified multiple times.")
+	dataTemplateGroup.BoolVar(&runSettings.ResolveRefs, "resolve-refs", false, "Expand ${.path.to.value} and ${env:VAR} references in string values of the merged data")
+	dataTemplateGroup.BoolVar(&runSettings.Prompt, "prompt", false, "Ask on the terminal for required values that are missing from the data, using the kind=schema inputs")
 	dataTemplateGroup.BoolVar(&runSettings.Helm, "helm", false, "Enable Helm-specific data processing (Convert keys specified with key=Chart to pascalcase)")
 
 	dataTemplateGroup.StringArrayVarP(
@@ -61,6 +69,8 @@ func initRoot(rootCommand *cobra.Command, runSettings *types.Arguments) {
 			"Can be specified multiple times.",
 	)
 	dataTemplateGroup.BoolVar(&runSettings.IncludeFilenames, "include-filenames", false, "Process filenames as templates")
+	dataTemplateGroup.StringArrayVar(&runSettings.CopyGlobs, "copy-glob", nil, "Copy the files of template directories and archives matching this glob as is instead of rendering them. Can be specified multiple times.")
+	dataTemplateGroup.StringArrayVar(&runSettings.TemplateGlobs, "template-glob", nil, "Only render the files of template directories and archives matching this glob, copying the others as is. Can be specified multiple times.")
 	dataTemplateGroup.BoolVar(&runSettings.AllowShell, "allow-shell", false, "Enable the 'shell' template function (execute arbitrary shell commands - use with caution)")
 
 	// Global Auth for any URL source
@@ -72,6 +82,7 @@ func initRoot(rootCommand *cobra.Command, runSettings *types.Arguments) {
 	outputGroup.BoolVarP(&runSettings.IgnoreEmpty, "ignore-empty", "", false, "Skip writing empty rendered template output to output location")
 	outputGroup.BoolVar(&runSettings.Strict, "strict", false, "On missing value, throw error instead of zero")
 	outputGroup.StringVar(&runSettings.DropExtension, "drop-extension", "tmpl", "Drop file extension from output filename before outputting")
+	outputGroup.StringVar(&runSettings.Coverage, "coverage", "", "Write a report of the template blocks executed to this file, as HTML if it ends in .html")
 
 	// Meta
 	systemGroup.BoolVarP(
@@ -82,6 +93,7 @@ func initRoot(rootCommand *cobra.Command, runSettings *types.Arguments) {
 		"Verbose output",
 	)
 	systemGroup.BoolVar(&runSettings.Version, "version", false, "Print the version and exit")
+	systemGroup.StringVar(&runSettings.ErrorFormat, "error-format", "text", "Format of errors printed to stderr, one of: text, json")
 
 	// Add groups to root command
 	rootCommand.Flags().AddFlagSet(dataTemplateGroup)
@@ -95,6 +107,21 @@ func initRoot(rootCommand *cobra.Command, runSettings *types.Arguments) {
 		systemGroup.AddFlag(h)
 	}
 
+	if dataCommand, _, err := rootCommand.Find([]string{"data"}); err == nil && dataCommand != rootCommand {
+		initDataCommand(dataCommand, runSettings, systemGroup)
+	}
+	if schemaCommand, _, err := rootCommand.Find([]string{"schema"}); err == nil && schemaCommand != rootCommand {
+		initSchemaCommand(schemaCommand, runSettings, systemGroup)
+	}
+	if newCommand, _, err := rootCommand.Find([]string{"new"}); err == nil && newCommand != rootCommand {
+		initNewCommand(newCommand, runSettings, systemGroup)
+	}
+	if lintCommand, _, err := rootCommand.Find([]string{"lint"}); err == nil && lintCommand != rootCommand {
+		initLintCommand(lintCommand, runSettings, systemGroup)
+	}
+	if testCommand, _, err := rootCommand.Find([]string{"test"}); err == nil && testCommand != rootCommand {
+		initTestCommand(testCommand, runSettings, systemGroup)
+	}
 }
 
 func main() {
@@ -107,12 +134,14 @@ func main() {
 	runData := &yutc.RunData{}
 	rootCommand := newRootCommand(settings, runData, &logger)
 	initRoot(rootCommand, settings)
+	yieldToTemplatePaths(rootCommand, os.Args[1:], &logger)
 
 	err := rootCommand.ExecuteContext(ctx)
 	if err != nil {
+		printError(os.Stderr, err, settings.ErrorFormat, isatty.IsTerminal(os.Stderr.Fd()) && os.Getenv("NO_COLOR") == "")
 		var exitErr *types.ExitError
 		if errors.As(err, &exitErr) {
-			logger.Error().Msg(exitErr.Error())
+			os.Exit(exitErr.Code)
 		}
 	}
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+
+	yutc "github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newNewCommand(settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) *cobra.Command {
+	newCommand := &cobra.Command{
+		Use:   "new [flags] <project-template> <destination>",
+		Short: "Create a new project from a project template",
+		Long: "Render a project template (a directory, archive or git repository) into a new directory, with filenames " +
+			"rendered as templates. A yutc.yaml manifest at the root of the template can declare a schema of the " +
+			"values to ask for, files to exclude and hooks to run after rendering.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runNew(cmd.Context(), settings, runData, logger, args[0], args[1])
+		},
+		SilenceUsage: true,
+	}
+	return newCommand
+}
+
+func initNewCommand(newCommand *cobra.Command, runSettings *types.Arguments, systemGroup *pflag.FlagSet) {
+	newCommand.Flags().SortFlags = false
+
+	dataGroup := pflag.NewFlagSet("Data & Templates", pflag.ContinueOnError)
+	outputGroup := pflag.NewFlagSet("Output & Rendering", pflag.ContinueOnError)
+
+	dataGroup.StringArrayVarP(
+		&runSettings.DataFiles,
+		"data",
+		"d",
+		nil,
+		"Data file with answers to the project template's questions. Can be specified multiple times and the inputs "+
+			"will be merged. See --help=syntax for more details.",
+	)
+	dataGroup.StringArrayVarP(
+		&runSettings.SetData,
+		"set",
+		"",
+		nil,
+		"Answer a question via a key path (path=value), parsing the value as JSON if possible. "+
+			"Can be specified multiple times.",
+	)
+	dataGroup.StringArrayVar(&runSettings.SetString, "set-string", nil, "Like --set, but the value is always a string")
+	dataGroup.StringArrayVar(&runSettings.SetFile, "set-file", nil, "Like --set, but the value is the contents of a file (path=./file)")
+	dataGroup.StringArrayVar(&runSettings.SetJSON, "set-json", nil, "Like --set, but the value must be valid JSON")
+	dataGroup.StringArrayVarP(
+		&runSettings.CommonTemplateFiles,
+		"common-templates",
+		"c",
+		nil,
+		"Templates to be shared across all files of the project template. Can be specified multiple times.",
+	)
+	dataGroup.StringArrayVar(&runSettings.CopyGlobs, "copy-glob", nil, "Copy the files of the project template matching this glob as is instead of rendering them. Can be specified multiple times.")
+	dataGroup.StringArrayVar(&runSettings.TemplateGlobs, "template-glob", nil, "Only render the files of the project template matching this glob, copying the others as is. Can be specified multiple times.")
+	dataGroup.BoolVar(&runSettings.AllowShell, "allow-shell", false, "Enable the 'shell' template function and run the post-render hooks of the project template (use with caution)")
+	dataGroup.StringVar(&runSettings.Auth, "auth", "", "Authentication for any URL source. Format: 'user:pass' for Basic Auth or 'token' for Bearer Token.")
+
+	outputGroup.BoolVarP(&runSettings.Overwrite, "overwrite", "w", false, "Render into a destination that is not empty, overwriting existing files")
+	outputGroup.BoolVar(&runSettings.Strict, "strict", false, "On missing value, throw error instead of zero")
+	outputGroup.StringVar(&runSettings.DropExtension, "drop-extension", "tmpl", "Drop file extension from output filename before outputting")
+
+	newCommand.Flags().AddFlagSet(dataGroup)
+	newCommand.Flags().AddFlagSet(outputGroup)
+
+	ConfigureHelp(newCommand, []*pflag.FlagSet{dataGroup, outputGroup, systemGroup})
+}
+
+func runNew(ctx context.Context, settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger, source, dest string) error {
+	app := yutc.NewApp(settings, runData, logger)
+	return app.New(ctx, source, dest)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCommand(t *testing.T) {
+	projectTemplate := map[string]string{
+		"template/yutc.yaml": "schema:\n" +
+			"  type: object\n" +
+			"  required: [project_name, python]\n" +
+			"  properties:\n" +
+			"    project_name: {type: string, description: Name of the project}\n" +
+			"    python: {type: string, default: '3.12'}\n" +
+			"    license: {type: string, default: MIT}\n" +
+			"exclude: [hooks, '*.bak']\n" +
+			"hooks:\n" +
+			"  post: ['echo done > hooked.txt']\n",
+		"template/{{ .project_name }}/__init__.py.tmpl": "name = \"{{ .project_name }}\"  # {{ .license }}\n",
+		"template/pyproject.toml":                       "requires-python = \">={{ .python }}\"\n",
+		"template/hooks/setup.sh":                       "echo skipped\n",
+		"template/notes.bak":                            "skipped\n",
+	}
+
+	runTest(t, &TestCase{
+		Name:       "New project from a directory with --set answers",
+		InputFiles: projectTemplate,
+		Args: func(rootDir string) []string {
+			return []string{"new", "--set", ".project_name=demo", filepath.Join(rootDir, "template"), filepath.Join(rootDir, "out")}
+		},
+		ExpectedFiles: map[string]string{
+			"out/demo/__init__.py": "name = \"demo\"  # MIT\n",
+			"out/pyproject.toml":   "requires-python = \">=3.12\"\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			for _, name := range []string{"yutc.yaml", "hooks", "notes.bak", "hooked.txt"} {
+				_, err := os.Stat(filepath.Join(rootDir, "out", name))
+				assert.True(t, os.IsNotExist(err), "%s should not be in the project", name)
+			}
+		},
+	})
+
+	if runtime.GOOS != "windows" {
+		runTest(t, &TestCase{
+			Name:       "New project runs hooks with --allow-shell",
+			InputFiles: projectTemplate,
+			Args: func(rootDir string) []string {
+				return []string{"new", "--allow-shell", "--set", ".project_name=demo", filepath.Join(rootDir, "template"), filepath.Join(rootDir, "out")}
+			},
+			ExpectedFiles: map[string]string{
+				"out/hooked.txt": "done\n",
+			},
+		})
+	}
+
+	runTest(t, &TestCase{
+		Name:       "New project without answers lists the missing values",
+		InputFiles: projectTemplate,
+		Args: func(rootDir string) []string {
+			return []string{"new", filepath.Join(rootDir, "template"), filepath.Join(rootDir, "out")}
+		},
+		ExpectedError: "stdin is not a terminal\n  /project_name: Name of the project",
+	})
+
+	runTest(t, &TestCase{
+		Name: "New project copies binary files and --copy-glob matches as is",
+		InputFiles: map[string]string{
+			"template/_helpers.tpl":           "{{ define \"greet\" }}hi {{ .name }}{{ end }}",
+			"template/{{ .name }}.txt.tmpl":   "{{ template \"greet\" . }}\n",
+			"template/assets/logo.png":        "\x89PNG\x00{{ .name }}\xff\n",
+			"template/static/index.html.tmpl": "<p>{{ .name }}</p>\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{"new", "--set", ".name=demo", "--copy-glob", "static/", filepath.Join(rootDir, "template"), filepath.Join(rootDir, "out")}
+		},
+		ExpectedFiles: map[string]string{
+			"out/demo.txt":               "hi demo\n",
+			"out/assets/logo.png":        "\x89PNG\x00{{ .name }}\xff\n",
+			"out/static/index.html.tmpl": "<p>{{ .name }}</p>\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			_, err := os.Stat(filepath.Join(rootDir, "out", "_helpers.tpl"))
+			assert.True(t, os.IsNotExist(err), "partials should not be in the project")
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name: "New project into a directory that is not empty",
+		InputFiles: map[string]string{
+			"template/a.txt": "a\n",
+			"out/b.txt":      "b\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{"new", filepath.Join(rootDir, "template"), filepath.Join(rootDir, "out")}
+		},
+		ExpectedError: "is not empty and `overwrite` is not set",
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	yutc "github.com/adam-huganir/yutc/pkg"
@@ -56,11 +57,40 @@ func newRootCommand(settings *types.Arguments, runData *yutc.RunData, logger *ze
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runRoot(cmd.Context(), settings, runData, logger, args)
 		},
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return validateErrorFormat(settings.ErrorFormat)
+		},
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
+		// errors are printed by main, in the format set by --error-format
+		SilenceErrors: true,
 	}
+	// subcommands share the namespace with template arguments, so keep cobra's generated ones out of it,
+	// see yieldToTemplatePaths for template arguments named like a subcommand
+	rootCommand.CompletionOptions.DisableDefaultCmd = true
+	rootCommand.AddCommand(newDataCommand(settings, runData, logger))
+	rootCommand.AddCommand(newSchemaCommand(settings, runData, logger))
+	rootCommand.AddCommand(newNewCommand(settings, runData, logger))
+	rootCommand.AddCommand(newLintCommand(settings, runData, logger))
+	rootCommand.AddCommand(newTestCommand(settings, runData, logger))
 	return rootCommand
 }
 
+// yieldToTemplatePaths removes the subcommand args would run when a file or directory of the same name
+// exists, so that `yutc -d values.yaml test` still renders ./test. It must be called after initRoot so that
+// the flags taking values are known when looking for the first positional argument.
+func yieldToTemplatePaths(rootCommand *cobra.Command, args []string, logger *zerolog.Logger) {
+	cmd, _, err := rootCommand.Find(args)
+	if err != nil || cmd == rootCommand || cmd.Parent() != rootCommand {
+		return
+	}
+	if _, err = os.Stat(cmd.Name()); err != nil {
+		return
+	}
+	logger.Debug().Msgf("%s exists, treating it as a template instead of the %s subcommand", cmd.Name(), cmd.Name())
+	rootCommand.RemoveCommand(cmd)
+}
+
 // ConfigureHelp sets up the custom help flags and usage printing with grouped flags.
 func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 	// Ensure the default help flag exists, then swap its Value to a custom bool-compatible type
@@ -106,8 +136,20 @@ func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 					  jsonpath
 					    Where to merge/nest the loaded data.
 					    Root node is optional(ex: .Secrets becomes $.Secrets).
+					    Required for data that is not an object at its root (ex: a JSON list or a CSV file).
 					    Alternately, if the kind key is set to "schema", this will specify where in the
 					    data to validate/resolve.
+					    For templates, the single object in the merged data the template is executed with,
+					    so the same template can render several parts of the data.
+
+					  out
+					    Templates only. The output file of the template, or the output directory of a
+					    template directory or archive, used instead of --output. '-' writes to stdout.
+					    Gives templates from stdin or URLs an output name of their own.
+
+					  mode
+					    Templates only. The octal permissions of the output files, ex: 0755. The mode
+					    of a template's front matter takes precedence.
 
 					  auth
 					    URL auth in one of these forms:
@@ -117,7 +159,28 @@ func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 
 					  kind
 					    Data modifier. Currently supports:
-					      "schema"      PARAMETER: defaults (true by default)
+					      "data"        PARAMETERS:
+					                      format (same as the format key below)
+					                        csv data is a list of rows and must be nested with jsonpath
+					                      documents ("merge" or "list") for yaml files with several
+					                        "---" documents, "merge" deep-merges them in order and "list"
+					                        loads them as a list that must be nested with jsonpath
+					                      template ("true" or "false") renders the file as a template
+					                        against the data merged before it, ex: values.yaml.tmpl
+					      "schema"      Validate the merged data with a JSON Schema (any draft), $refs to other
+					                    schema files or URLs are resolved relative to it.
+					                    PARAMETER: defaults (true by default)
+					      "secret"      Decrypt a sops encrypted yaml or json file (age or pgp) in memory.
+					                    Decrypted values are redacted in logs. PARAMETERS:
+					                      keyfile (file with age identities or an armored pgp private key)
+					                      keyenv (environment variable with the same)
+					                      format (same as the format key below)
+					                    Defaults to $SOPS_AGE_KEY, $SOPS_AGE_KEY_FILE, or the sops age keys.txt
+
+					  format
+					    Data format override for inputs whose extension doesn't identify it (stdin, URLs, etc).
+					    One of yaml, json, toml, hcl, ini, env, csv, xml. When not given, the format is detected
+					    from the file extension, then the content type reported by a URL, then the content itself.
 
 					  type
 					    Explicit source kind override. Supports:
@@ -125,6 +188,11 @@ func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 					      "url"
 					      "stdin"
 					      "git"         PARAMETER: submodules (false by default)
+					      "env"         Data from environment variables, src must be "env". PARAMETERS:
+					                      prefix (required, only variables with this prefix are included and it is removed)
+					                      separator (splits names into nested keys, "__" by default)
+					                    Keys are lowercased and values are parsed as JSON like --set values,
+					                    ex: APP_DB__HOST=x with prefix=APP_ becomes {db: {host: x}}
 
 					Notes:
 					  - Field separator is ','
@@ -137,7 +205,15 @@ func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 					  yutc -d ./values.yaml ./tmpl.tmpl
 					  yutc -d jsonpath=.Secrets,src=./secrets.yaml ./tmpl.tmpl
 					  yutc -d src=./schema.yaml,kind=schema(defaults=false) ./tmpl.tmpl
+					  yutc -d jsonpath=.hosts,src=./inventory.txt,kind=data(format=csv) ./tmpl.tmpl
+					  cat values.toml | yutc -d src=-,format=toml ./tmpl.tmpl
+					  yutc -d jsonpath=.manifests,src=./all.yaml,kind=data(documents=list) ./tmpl.tmpl
+					  yutc -d ./values.yaml -d src=./derived.yaml.tmpl,kind=data(template=true) ./tmpl.tmpl
+					  yutc -d ./values.yaml -d src=env,type=env(prefix=APP_) ./tmpl.tmpl
+					  yutc -d jsonpath=.secrets,src=./secrets.sops.yaml,kind=secret(keyfile=./age.txt) ./tmpl.tmpl
 					  yutc -d jsonpath=.Remote,src=https://example.com/data.yaml,auth=adam:mypass ./tmpl.tmpl
+					  yutc -d ./values.yaml src=./svc.tmpl,jsonpath=.services.web,out=web.yaml
+					  cat run.sh.tmpl | yutc -d ./values.yaml -o ./out src=-,out=./bin/run.sh,mode=0755 ./app.tmpl
 				`))
 				return
 			default:
@@ -160,6 +236,16 @@ func ConfigureHelp(cmd *cobra.Command, groups []*pflag.FlagSet) {
 			fmt.Fprintf(c.OutOrStdout(), "\n%s\n\n", c.Long)
 		}
 
+		if c.HasAvailableSubCommands() {
+			fmt.Fprintf(c.OutOrStdout(), "Commands:\n")
+			for _, sub := range c.Commands() {
+				if sub.IsAvailableCommand() && sub.Name() != "help" {
+					fmt.Fprintf(c.OutOrStdout(), "  %-10s %s\n", sub.Name(), sub.Short)
+				}
+			}
+			fmt.Fprintln(c.OutOrStdout())
+		}
+
 		// Print grouped flags using FlagSet.FlagUsages() which wraps natively
 		for _, g := range groups {
 			if g.HasFlags() {
@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -38,3 +40,22 @@ func TestHelpTopic(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, strings.Contains(buf.String(), "Argument syntax help"))
 }
+
+func TestTemplateNamedLikeSubcommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	assert.NoError(t, os.Mkdir("test", 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join("test", "hello.tmpl"), []byte("hello {{ .name }}"), 0o644))
+	assert.NoError(t, os.WriteFile("values.yaml", []byte("name: world\n"), 0o644))
+
+	cmd, ctx := newCmdTest(&types.Arguments{}, []string{"-d", "values.yaml", "-o", "out", "test"})
+	assert.NoError(t, cmd.ExecuteContext(ctx))
+	content, err := os.ReadFile("out")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+
+	// without a ./lint the subcommand still runs
+	cmd, _ = newCmdTest(&types.Arguments{}, []string{"lint", "test"})
+	lintCommand, _, err := cmd.Find([]string{"lint", "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, "lint", lintCommand.Name())
+}
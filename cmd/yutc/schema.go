@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+
+	yutc "github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newSchemaCommand(settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) *cobra.Command {
+	schemaCommand := &cobra.Command{
+		Use:   "schema",
+		Short: "Work with JSON Schemas of template data",
+		Args:  cobra.NoArgs,
+	}
+	schemaCommand.AddCommand(&cobra.Command{
+		Use:   "infer [flags] <templates...>",
+		Short: "Generate a JSON Schema from the data used by templates",
+		Long: "Parse templates without rendering them and output a skeleton JSON Schema of the data they use, " +
+			"following field chains through index, with, range, variables and template/include calls.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchemaInfer(cmd.Context(), settings, runData, logger, args)
+		},
+		SilenceUsage: true,
+	})
+	return schemaCommand
+}
+
+func initSchemaCommand(schemaCommand *cobra.Command, runSettings *types.Arguments, systemGroup *pflag.FlagSet) {
+	// schema only groups its subcommands, so it has none of the render flags of the root command
+	ConfigureHelp(schemaCommand, []*pflag.FlagSet{systemGroup})
+
+	inferCommand, _, err := schemaCommand.Find([]string{"infer"})
+	if err != nil || inferCommand == schemaCommand {
+		return
+	}
+	inferCommand.Flags().SortFlags = false
+
+	templateGroup := pflag.NewFlagSet("Templates", pflag.ContinueOnError)
+	outputGroup := pflag.NewFlagSet("Output", pflag.ContinueOnError)
+
+	templateGroup.StringArrayVarP(
+		&runSettings.CommonTemplateFiles,
+		"common-templates",
+		"c",
+		nil,
+		"Templates to be shared across all arguments in template list. Can be a file or a URL. Can be specified multiple times.",
+	)
+	templateGroup.StringArrayVar(&runSettings.CopyGlobs, "copy-glob", nil, "Skip the files of template directories and archives matching this glob, as they are copied as is instead of rendered. Can be specified multiple times.")
+	templateGroup.StringArrayVar(&runSettings.TemplateGlobs, "template-glob", nil, "Only parse the files of template directories and archives matching this glob, as the others are copied as is. Can be specified multiple times.")
+	templateGroup.StringVar(&runSettings.Auth, "auth", "", "Authentication for any URL source. Format: 'user:pass' for Basic Auth or 'token' for Bearer Token.")
+
+	outputGroup.StringVarP(&runSettings.SchemaFormat, "format", "f", "json", "Output format, one of: json, yaml")
+	outputGroup.StringVarP(&runSettings.Output, "output", "o", "-", "Output file, defaults to stdout")
+	outputGroup.BoolVarP(&runSettings.Overwrite, "overwrite", "w", false, "Overwrite existing files")
+
+	inferCommand.Flags().AddFlagSet(templateGroup)
+	inferCommand.Flags().AddFlagSet(outputGroup)
+
+	ConfigureHelp(inferCommand, []*pflag.FlagSet{templateGroup, outputGroup, systemGroup})
+}
+
+func runSchemaInfer(ctx context.Context, settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger, args []string) error {
+	app := yutc.NewApp(settings, runData, logger)
+	return app.InferSchema(ctx, args)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaHelp(t *testing.T) {
+	for _, args := range [][]string{{"schema", "--help"}, {"schema", "infer", "--help"}} {
+		settings := &types.Arguments{}
+		cmd := newRootCommand(settings, &yutc.RunData{}, &logger)
+		initRoot(cmd, settings)
+
+		buf := new(bytes.Buffer)
+		cmd.SetOut(buf)
+		cmd.SetErr(buf)
+		cmd.SetArgs(args)
+
+		assert.NoError(t, cmd.ExecuteContext(context.Background()))
+		assert.Contains(t, buf.String(), "System:")
+		assert.NotContains(t, buf.String(), "--data")
+		assert.NotContains(t, buf.String(), "--strict")
+	}
+}
+
+func TestSchemaInferCommand(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "Schema inferred from templates to yaml",
+		InputFiles: map[string]string{
+			"app.yaml.tmpl": "name: {{ .app.name }}\n{{ range .app.ports }}- {{ .port }}\n{{ end }}",
+		},
+		Args: func(rootDir string) []string {
+			return []string{"schema", "infer", "-f", "yaml", filepath.Join(rootDir, "app.yaml.tmpl")}
+		},
+		ExpectedStdout: "$schema: https://json-schema.org/draft/2020-12/schema\n" +
+			"properties:\n" +
+			"  app:\n" +
+			"    properties:\n" +
+			"      name: true\n" +
+			"      ports:\n" +
+			"        additionalProperties:\n" +
+			"          properties:\n" +
+			"            port: true\n" +
+			"          type: object\n" +
+			"        items:\n" +
+			"          properties:\n" +
+			"            port: true\n" +
+			"          type: object\n" +
+			"        type:\n" +
+			"        - array\n" +
+			"        - object\n" +
+			"    type: object\n" +
+			"type: object\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Schema infer skips --copy-glob files",
+		InputFiles: map[string]string{
+			"src/app.yaml.tmpl": "name: {{ .name }}\n",
+			"src/page.html":     "<p>{{ .page }}</p>\n",
+		},
+		Args: func(rootDir string) []string {
+			return []string{"schema", "infer", "-f", "yaml", "--copy-glob", "*.html", filepath.Join(rootDir, "src")}
+		},
+		ExpectedStdout: "$schema: https://json-schema.org/draft/2020-12/schema\n" +
+			"properties:\n" +
+			"  name: true\n" +
+			"type: object\n",
+	})
+
+	runTest(t, &TestCase{
+		Name: "Schema infer requires templates",
+		Args: func(_ string) []string {
+			return []string{"schema", "infer"}
+		},
+		ExpectedError: "requires at least 1 arg(s)",
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+
+	yutc "github.com/adam-huganir/yutc/pkg"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newTestCommand(settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger) *cobra.Command {
+	testCommand := &cobra.Command{
+		Use:   "test [flags] [suites...]",
+		Short: "Run template test suites",
+		Long: "Run the test suites in the given YAML files, or the *.yaml and *.yml files of the given directories " +
+			"(./tests by default). A suite declares the data, --set values and templates to render, as paths " +
+			"relative to the suite file, and tests asserting on the output: snapshots, values at JSONPaths of the " +
+			"output parsed as YAML, or rendering errors. Exits with status 1 if any test fails.",
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTestSuites(cmd.Context(), settings, runData, logger, args)
+		},
+		SilenceUsage: true,
+	}
+	return testCommand
+}
+
+func initTestCommand(testCommand *cobra.Command, runSettings *types.Arguments, systemGroup *pflag.FlagSet) {
+	testCommand.Flags().SortFlags = false
+
+	testGroup := pflag.NewFlagSet("Tests", pflag.ContinueOnError)
+	outputGroup := pflag.NewFlagSet("Output", pflag.ContinueOnError)
+
+	testGroup.BoolVar(&runSettings.UpdateSnapshots, "update-snapshots", false, "Write the rendered output to missing or differing snapshots instead of failing")
+	testGroup.StringVar(&runSettings.Coverage, "coverage", "", "Write a report of the template blocks executed by all tests to this file, as HTML if it ends in .html")
+	testGroup.BoolVar(&runSettings.AllowShell, "allow-shell", false, "Allow templates to use the 'shell' template function")
+	testGroup.StringVar(&runSettings.Auth, "auth", "", "Authentication for any URL source. Format: 'user:pass' for Basic Auth or 'token' for Bearer Token.")
+
+	outputGroup.StringVarP(&runSettings.TestFormat, "format", "f", "text", "Report format, one of: text, junit")
+	outputGroup.StringVarP(&runSettings.Output, "output", "o", "-", "Output file, defaults to stdout")
+	outputGroup.BoolVarP(&runSettings.Overwrite, "overwrite", "w", false, "Overwrite existing files")
+
+	testCommand.Flags().AddFlagSet(testGroup)
+	testCommand.Flags().AddFlagSet(outputGroup)
+
+	ConfigureHelp(testCommand, []*pflag.FlagSet{testGroup, outputGroup, systemGroup})
+}
+
+func runTestSuites(ctx context.Context, settings *types.Arguments, runData *yutc.RunData, logger *zerolog.Logger, args []string) error {
+	app := yutc.NewApp(settings, runData, logger)
+	return app.Test(ctx, args)
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestCommand(t *testing.T) {
+	inputFiles := map[string]string{
+		"values.yaml":         "name: demo\nreplicas: 1\n",
+		"src/app.yaml.tmpl":   "name: {{ .name }}\nreplicas: {{ .replicas }}\n",
+		"src/conf.tmpl":       "{{ .name | upper }}\n",
+		"tests/expected/conf": "DEMO\n",
+		"fail/broken.yaml":    "data: [../values.yaml]\ntemplates: [../src]\ntests:\n  - name: broken\n    asserts:\n      - equal: {template: app.yaml, path: .replicas, value: 9}\n",
+		"tests/app.yaml": `data: [../values.yaml]
+templates: [../src]
+tests:
+  - name: defaults
+    asserts:
+      - snapshot: {template: app.yaml}
+      - snapshot: {template: conf, file: expected/conf}
+      - equal: {template: app.yaml, path: .name, value: demo}
+  - name: replicas
+    set: [.replicas=3]
+    templates: [../src/app.yaml.tmpl]
+    asserts:
+      - equal: {path: .replicas, value: 3}
+  - name: missing value
+    set-json: ['.name=null']
+    asserts:
+      - exists: {template: conf, path: .a}
+`,
+	}
+
+	runTest(t, &TestCase{
+		Name:       "Test runs suites relative to their file and updates snapshots",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"test", "--update-snapshots", "-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, "tests")}
+		},
+		ExpectedError: "1 of 3 test(s) failed",
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+			require.NoError(t, err)
+			assert.Regexp(t, `(?m)^PASS  app/defaults \(`, string(report))
+			assert.Regexp(t, `(?m)^PASS  app/replicas \(`, string(report))
+			assert.Regexp(t, `(?m)^ERROR app/missing value \(`, string(report))
+			assert.Contains(t, string(report), "3 tests, 2 passed, 0 failed, 1 errors\n")
+
+			snapshots, err := os.ReadFile(filepath.Join(rootDir, "tests", "__snapshot__", "app.snap"))
+			require.NoError(t, err)
+			assert.Equal(t, "defaults:\n  app.yaml: |\n    name: demo\n    replicas: 1\n", string(snapshots))
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name:       "Test reports failures as JUnit XML",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"test", "-f", "junit", "-o", filepath.Join(rootDir, "report.xml"), filepath.Join(rootDir, "fail", "broken.yaml")}
+		},
+		ExpectedError: "1 of 1 test(s) failed",
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.xml"))
+			require.NoError(t, err)
+			assert.Contains(t, string(report), `<testsuite name="broken" file="`+filepath.Join(rootDir, "fail", "broken.yaml")+`" tests="1" failures="1" errors="0"`)
+			assert.Contains(t, string(report), `<failure message="assert 1: app.yaml: .replicas is 1, expected 9">`)
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name:       "Test writes the coverage of all tests",
+		InputFiles: inputFiles,
+		Args: func(rootDir string) []string {
+			return []string{"test", "--coverage", filepath.Join(rootDir, "coverage.html"), "-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, "fail")}
+		},
+		ExpectedError: "1 of 1 test(s) failed",
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "coverage.html"))
+			require.NoError(t, err)
+			assert.Contains(t, string(report), "<h1>Template coverage: 100.0% of 2 blocks executed</h1>")
+			assert.Contains(t, string(report), filepath.Join(rootDir, "src", "app.yaml.tmpl"))
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name: "Test renders templates at their jsonpath and out",
+		InputFiles: map[string]string{
+			"values.yaml":    "services:\n  web: {port: 80}\n  db: {port: 5432}\n",
+			"src/svc.tmpl":   "port: {{ .port }}\n",
+			"src/dir/a.yaml": "db: {{ .port }}\n",
+			"tests/svc.yaml": `data: [../values.yaml]
+tests:
+  - name: scoped
+    templates:
+      - src=../src/svc.tmpl,jsonpath=.services.web,out=web.yaml
+      - src=../src/svc.tmpl,jsonpath=.services.db,out=db/svc.yaml
+      - src=../src/dir,jsonpath=.services.db,out=conf
+    asserts:
+      - equal: {template: web.yaml, path: .port, value: 80}
+      - equal: {template: db/svc.yaml, path: .port, value: 5432}
+      - equal: {template: conf/a.yaml, path: .db, value: 5432}
+`,
+		},
+		Args: func(rootDir string) []string {
+			return []string{"test", "-o", filepath.Join(rootDir, "report.txt"), filepath.Join(rootDir, "tests")}
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "report.txt"))
+			require.NoError(t, err)
+			assert.Contains(t, string(report), "1 tests, 1 passed, 0 failed, 0 errors\n", string(report))
+		},
+	})
+
+	runTest(t, &TestCase{
+		Name: "Test rejects an unknown format",
+		Args: func(rootDir string) []string {
+			return []string{"test", "-f", "tap", rootDir}
+		},
+		ExpectedError: `invalid test format "tap": must be one of: text, junit`,
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var expectedOutputs = map[string]string{
@@ -32,6 +34,7 @@ func newCmdTest(settings *types.Arguments, args []string) (*cobra.Command, conte
 	cmd := newRootCommand(settings, &runData, &logger)
 	cmd.SetArgs(args)
 	initRoot(cmd, settings)
+	yieldToTemplatePaths(cmd, args, &logger)
 
 	ctx := context.Background()
 	return cmd, ctx
@@ -576,6 +579,361 @@ func TestSchema(t *testing.T) {
 	})
 }
 
+func TestCoverage(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "render writes a coverage report",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".on=true", "--coverage", filepath.Join(rootDir, "coverage.txt"), "-o", "-", filepath.Join(rootDir, "app.tmpl")}
+		},
+		InputFiles: map[string]string{
+			"app.tmpl": "{{ if .on }}on{{ else }}off{{ end }}",
+		},
+		ExpectedStdout: "on",
+		Verify: func(t *testing.T, rootDir string) {
+			report, err := os.ReadFile(filepath.Join(rootDir, "coverage.txt"))
+			require.NoError(t, err)
+			path := filepath.Join(rootDir, "app.tmpl")
+			assert.Equal(t, path+": 2 of 3 blocks executed (66.7%)\n"+
+				"  1:1          1  template\n"+
+				"  1:1          1  if .on\n"+
+				"  1:15         0  else of if .on\n"+
+				"total: 2 of 3 blocks executed (66.7%)\n", string(report))
+		},
+	})
+}
+
+func TestFrontMatter(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "front matter sets the output path, mode, data defaults, skip and overwrite",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".name=app", "-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/run.sh.tmpl":     "--- # yutc\noutput: 'bin/{{ .name }}.sh'\nmode: 0755\ndata:\n  greeting: hello\n---\necho {{ .greeting }} {{ .name }}\n",
+			"src/debug.yaml.tmpl": "--- # yutc\nskip: '{{ not .debug }}'\n---\ndebug: true\n",
+			"src/keep.txt.tmpl":   "--- # yutc\noverwrite: true\n---\nnew\n",
+			"out/keep.txt":        "old\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/bin/app.sh": "echo hello app\n",
+			"out/keep.txt":   "new\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			info, err := os.Stat(filepath.Join(rootDir, "out", "bin", "app.sh"))
+			require.NoError(t, err)
+			if runtime.GOOS != "windows" {
+				assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+			}
+			assert.NoFileExists(t, filepath.Join(rootDir, "out", "debug.yaml"))
+			assert.NoFileExists(t, filepath.Join(rootDir, "out", "run.sh"))
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "a yaml document starting with --- is not front matter",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".kind=Service", filepath.Join(rootDir, "doc.yaml.tmpl")}
+		},
+		InputFiles: map[string]string{
+			"doc.yaml.tmpl": "---\ndata: foo\n---\nkind: {{ .kind }}\n",
+		},
+		ExpectedStdout: "---\ndata: foo\n---\nkind: Service\n",
+	})
+	runTest(t, &TestCase{
+		Name: "front matter output must stay in the output directory",
+		Args: func(rootDir string) []string {
+			return []string{"-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/a.tmpl": "--- # yutc\noutput: ../a\n---\na\n",
+			"src/b.tmpl": "b\n",
+		},
+		ExpectedError: `invalid front matter output of`,
+	})
+}
+
+func TestCopyFiles(t *testing.T) {
+	// the harness normalizes line endings, so this has none
+	png := "\x89PNG\x00\x00\x00\x0dIHDR{{ .name }}"
+	runTest(t, &TestCase{
+		Name: "files that are not text or match --copy-glob are copied as is",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".name=app", "--copy-glob", "*.html", "-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/app.yaml.tmpl": "name: {{ .name }}\n",
+			"src/logo.png":      png,
+			"src/page.html":     "<p>{{ not a template }}</p>\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/app.yaml":  "name: app\n",
+			"out/logo.png":  png,
+			"out/page.html": "<p>{{ not a template }}</p>\n",
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "only files matching --template-glob are rendered",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".name=app", "--template-glob", "*.tmpl", "-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/app.yaml.tmpl": "name: {{ .name }}\n",
+			"src/notes.txt":     "{{ .name }}\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/app.yaml":  "name: app\n",
+			"out/notes.txt": "{{ .name }}\n",
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "invalid globs are rejected",
+		Args: func(rootDir string) []string {
+			return []string{"--copy-glob", "[a", "-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/a.tmpl": "a",
+		},
+		ExpectedError: `invalid glob "[a"`,
+	})
+
+	t.Run("copies keep their mode", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("file modes are not supported on windows")
+		}
+		rootDir := t.TempDir()
+		src := filepath.Join(rootDir, "src")
+		require.NoError(t, os.MkdirAll(src, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "a.tmpl"), []byte("a"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(src, "run.sh"), []byte("echo {{ x }}\n"), 0o750))
+		require.NoError(t, os.Chmod(filepath.Join(src, "run.sh"), 0o750))
+		_, err := runYutcAndCaptureStdout([]string{"--copy-glob", "*.sh", "-o", filepath.Join(rootDir, "out"), src})
+		require.NoError(t, err)
+		info, err := os.Stat(filepath.Join(rootDir, "out", "run.sh"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	})
+}
+
+func TestContainerFilters(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "exclude, include, .yutcignore and partials filter the files of a directory",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".name=app", "-o", filepath.Join(rootDir, "out"),
+				"src=" + filepath.Join(rootDir, "src") + ",exclude=*.md,exclude=.github"}
+		},
+		InputFiles: map[string]string{
+			"src/app.yaml.tmpl":       "name: {{ include \"name\" . }}\n",
+			"src/_helpers.tpl":        "{{ define \"name\" }}{{ .name }}{{ end }}",
+			"src/README.md":           "# templates\n",
+			"src/.github/ci.yaml":     "on: push\n",
+			"src/.git/config":         "[core]\n",
+			"src/app.yaml.tmpl.swp":   "swap\n",
+			"src/.yutcignore":         "# editor files\n*.swp\n",
+			"src/values.schema.json":  "{}\n",
+			"src/notes/todo.txt.tmpl": "todo\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/app.yaml":           "name: app\n",
+			"out/values.schema.json": "{}\n",
+			"out/notes/todo.txt":     "todo\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			for _, name := range []string{"README.md", ".github", ".git", "app.yaml.tmpl.swp", ".yutcignore", "_helpers.tpl", "_helpers"} {
+				assert.NoFileExists(t, filepath.Join(rootDir, "out", name))
+				assert.NoDirExists(t, filepath.Join(rootDir, "out", name))
+			}
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "include keeps only the matching files",
+		Args: func(rootDir string) []string {
+			return []string{"-o", filepath.Join(rootDir, "out"), "src=" + filepath.Join(rootDir, "src") + ",include=*.tmpl"}
+		},
+		InputFiles: map[string]string{
+			"src/a.tmpl":  "a\n",
+			"src/b.tmpl":  "b\n",
+			"src/c.txt":   "c\n",
+			"src/d/e.txt": "e\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/a": "a\n",
+			"out/b": "b\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			assert.NoFileExists(t, filepath.Join(rootDir, "out", "c.txt"))
+			assert.NoFileExists(t, filepath.Join(rootDir, "out", "d", "e.txt"))
+		},
+	})
+}
+
+func TestTemplatedPaths(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "directory names are templated and empty segments skip files and directories",
+		Args: func(rootDir string) []string {
+			return []string{"--include-filenames", "--set", ".name=app", "--set", ".docs=false",
+				"-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/{{ .name }}/main.go.tmpl":                         "package {{ .name }}\n",
+			"src/{{ .name }}/nested/util.go":                       "package nested\n",
+			"src/{{ if .docs }}docs{{ end }}/index.md":             "# {{ .name }}\n",
+			"src/{{ if .docs }}CHANGELOG.md{{ end }}":              "changes\n",
+			"src/{{ if not .docs }}README.md{{ end }}":             "# {{ .name }}\n",
+			"src/{{ if .docs }}docs{{ end }}/nested/guide/more.md": "more\n",
+		},
+		ExpectedFiles: map[string]string{
+			"out/app/main.go":        "package app\n",
+			"out/app/nested/util.go": "package nested\n",
+			"out/README.md":          "# app\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			entries, err := os.ReadDir(filepath.Join(rootDir, "out"))
+			require.NoError(t, err)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			assert.ElementsMatch(t, []string{"app", "README.md"}, names)
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "templates rendering to the same path are reported",
+		Args: func(rootDir string) []string {
+			return []string{"--include-filenames", "--set", ".a=same", "--set", ".b=same",
+				"-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/{{ .a }}.txt": "a\n",
+			"src/{{ .b }}.txt": "b\n",
+		},
+		ExpectedError: "both render to",
+		Verify: func(t *testing.T, rootDir string) {
+			assert.NoDirExists(t, filepath.Join(rootDir, "out"), "nothing is written when outputs collide")
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "a front matter output colliding with another template is reported",
+		Args: func(rootDir string) []string {
+			return []string{"-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/config.yaml": "a: 1\n",
+			"src/other.tmpl":  "--- # yutc\noutput: config.yaml\n---\nb: 2\n",
+		},
+		ExpectedError: "both render to",
+		Verify: func(t *testing.T, rootDir string) {
+			assert.NoDirExists(t, filepath.Join(rootDir, "out"), "nothing is written when outputs collide")
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "a template colliding with a copied file is reported",
+		Args: func(rootDir string) []string {
+			return []string{"--copy-glob", "*.txt", "-o", filepath.Join(rootDir, "out"), filepath.Join(rootDir, "src")}
+		},
+		InputFiles: map[string]string{
+			"src/notes.txt":  "as is\n",
+			"src/notes.tmpl": "--- # yutc\noutput: notes.txt\n---\nrendered\n",
+		},
+		ExpectedError: "both render to",
+		Verify: func(t *testing.T, rootDir string) {
+			assert.NoDirExists(t, filepath.Join(rootDir, "out"), "nothing is written when outputs collide")
+		},
+	})
+}
+
+func TestTemplateJSONPath(t *testing.T) {
+	services := "services:\n  api:\n    port: 80\n  web:\n    port: 8080\n"
+	runTest(t, &TestCase{
+		Name: "the same template renders subtrees of the data to the outputs named by out=",
+		Args: func(rootDir string) []string {
+			return []string{"-d", filepath.Join(rootDir, "data.yaml"),
+				"src=" + filepath.Join(rootDir, "svc.tmpl") + ",jsonpath=.services.api,out=" + filepath.Join(rootDir, "api.yaml"),
+				"src=" + filepath.Join(rootDir, "svc.tmpl") + ",jsonpath=.services.web,out=" + filepath.Join(rootDir, "web.yaml")}
+		},
+		InputFiles: map[string]string{
+			"data.yaml": services,
+			"svc.tmpl":  "port: {{ .port }}\n",
+		},
+		ExpectedFiles: map[string]string{
+			"api.yaml": "port: 80\n",
+			"web.yaml": "port: 8080\n",
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "out= of a directory is the output directory of its files",
+		Args: func(rootDir string) []string {
+			return []string{"-d", filepath.Join(rootDir, "data.yaml"), "-o", filepath.Join(rootDir, "default"),
+				"src=" + filepath.Join(rootDir, "src") + ",jsonpath=.services.web,out=" + filepath.Join(rootDir, "web"),
+				filepath.Join(rootDir, "root.tmpl")}
+		},
+		InputFiles: map[string]string{
+			"data.yaml":      services,
+			"src/svc.tmpl":   "port: {{ .port }}\n",
+			"src/static.txt": "static\n",
+			"root.tmpl":      "{{ len .services }}\n",
+		},
+		ExpectedFiles: map[string]string{
+			"web/svc":        "port: 8080\n",
+			"web/static.txt": "static\n",
+			"default/root":   "2\n",
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "a jsonpath selecting nothing is an error",
+		Args: func(rootDir string) []string {
+			return []string{"-d", filepath.Join(rootDir, "data.yaml"), "src=" + filepath.Join(rootDir, "svc.tmpl") + ",jsonpath=.services.db"}
+		},
+		InputFiles: map[string]string{
+			"data.yaml": services,
+			"svc.tmpl":  "port: {{ .port }}\n",
+		},
+		ExpectedError: "selects nothing in the data",
+	})
+}
+
+func TestTemplateOutputArgs(t *testing.T) {
+	runTest(t, &TestCase{
+		Name: "out=- writes one template to stdout and mode= sets the mode of the others",
+		Args: func(rootDir string) []string {
+			return []string{"--set", ".name=app", "-o", filepath.Join(rootDir, "out"),
+				"src=" + filepath.Join(rootDir, "notes.tmpl") + ",out=-",
+				"src=" + filepath.Join(rootDir, "run.sh.tmpl") + ",mode=0755",
+				"src=" + filepath.Join(rootDir, "bin") + ",mode=0700"}
+		},
+		InputFiles: map[string]string{
+			"notes.tmpl":       "notes for {{ .name }}\n",
+			"run.sh.tmpl":      "echo {{ .name }}\n",
+			"bin/start.sh":     "start {{ .name }}\n",
+			"bin/config.tmpl":  "--- # yutc\nmode: 0600\n---\nname: {{ .name }}\n",
+			"bin/static.bytes": "\x00\x01",
+		},
+		ExpectedStdout: "notes for app\n",
+		ExpectedFiles: map[string]string{
+			"out/run.sh":   "echo app\n",
+			"out/start.sh": "start app\n",
+			"out/config":   "name: app\n",
+		},
+		Verify: func(t *testing.T, rootDir string) {
+			assert.NoFileExists(t, filepath.Join(rootDir, "out", "notes"))
+			if runtime.GOOS == "windows" {
+				return
+			}
+			for name, mode := range map[string]os.FileMode{"run.sh": 0o755, "start.sh": 0o700, "config": 0o600, "static.bytes": 0o700} {
+				info, err := os.Stat(filepath.Join(rootDir, "out", name))
+				require.NoError(t, err)
+				assert.Equal(t, mode, info.Mode().Perm(), name)
+			}
+		},
+	})
+	runTest(t, &TestCase{
+		Name: "mode= must be octal",
+		Args: func(rootDir string) []string {
+			return []string{"src=" + filepath.Join(rootDir, "run.sh.tmpl") + ",mode=rwx"}
+		},
+		InputFiles:    map[string]string{"run.sh.tmpl": "echo\n"},
+		ExpectedError: "invalid mode rwx",
+	})
+}
+
 type TestCase struct {
 	Name           string
 	Args           func(rootDir string) []string
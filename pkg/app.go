@@ -4,19 +4,28 @@ package yutc
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
 
 	"github.com/adam-huganir/yutc/pkg/config"
+	"github.com/adam-huganir/yutc/pkg/coverage"
 	"github.com/adam-huganir/yutc/pkg/data"
+	"github.com/adam-huganir/yutc/pkg/lint"
 	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/adam-huganir/yutc/pkg/scaffold"
+	"github.com/adam-huganir/yutc/pkg/schema"
 	yutcTemplate "github.com/adam-huganir/yutc/pkg/templates"
 	"github.com/adam-huganir/yutc/pkg/types"
 	"github.com/goccy/go-yaml"
+	"github.com/mattn/go-isatty"
 	"github.com/rs/zerolog"
+	"github.com/theory/jsonpath/spec"
 )
 
 // App holds the application state and dependencies for template execution.
@@ -25,6 +34,9 @@ type App struct {
 	RunData  *RunData
 	Logger   *zerolog.Logger
 	TempDir  string
+
+	baseDir  string            // relative input paths are resolved against it rather than the working directory, if set
+	coverage *coverage.Profile // the blocks of templates executed, with --coverage
 }
 
 // NewApp creates a new App instance with the provided settings, run data, and logger.
@@ -59,33 +71,113 @@ func (app *App) Run(_ context.Context, args []string) (err error) {
 		app.Logger.Fatal().Msg("No template files specified")
 	}
 
-	// grab the name of a temp directory to use for processing, but it is not guaranteed to exist yet
-	tempDir := app.TempDir
-	defer func() {
-		if exists, err := loader.Exists(tempDir); exists {
-			if err != nil {
-				app.Logger.Error().Err(err).Msg("failed to check if temp directory exists")
-			}
-			_ = os.RemoveAll(tempDir)
-		}
-	}()
+	defer app.cleanupTempDir()
+	globalAuth := app.globalAuth()
 
-	globalAuth := loader.ParseAuthString(app.Settings.Auth)
-	if globalAuth.BasicAuth != "" || globalAuth.BearerToken != "" {
-		globalAuth.Lazy = true
+	if err = app.resolveTemplateFiles(globalAuth); err != nil {
+		return err
 	}
 
-	app.RunData.TemplateFiles, err = yutcTemplate.ResolveTemplatePaths(app.Settings.TemplatePaths, false, tempDir, app.Logger)
+	err = app.resolveDataFiles(globalAuth)
 	if err != nil {
 		return err
 	}
-	for _, tf := range app.RunData.TemplateFiles {
-		if !tf.Auth.Disabled && tf.Auth.BasicAuth == "" && tf.Auth.BearerToken == "" {
-			tf.Auth = globalAuth
+
+	err = config.ValidateArguments(app.Settings, &config.ParsedInputs{
+		DataFiles:           app.RunData.DataFiles,
+		TemplateFiles:       app.RunData.TemplateFiles,
+		CommonTemplateFiles: app.RunData.CommonTemplateFiles,
+	}, app.Logger)
+	if err != nil {
+		return err
+	}
+
+	if err = app.mergeData(); err != nil {
+		return err
+	}
+
+	if app.Settings.Coverage != "" {
+		app.coverage = coverage.NewProfile()
+	}
+	if err = app.renderTemplates(); err != nil {
+		return err
+	}
+	return app.writeCoverage()
+}
+
+// New renders the project template at source, a directory, archive or git repository, into the new
+// directory dest. Values required by the schema of the template's manifest are taken from the data inputs
+// and --set flags, or prompted for, and its post-render hooks are run in dest if shell commands are allowed.
+func (app *App) New(_ context.Context, source, dest string) (err error) {
+	app.Settings.TemplatePaths = []string{source}
+	app.Settings.Output = dest
+	app.Settings.IncludeFilenames = true
+	if app.Logger.GetLevel() < zerolog.DebugLevel {
+		app.LogSettings()
+	}
+
+	if isDir, err := loader.IsDir(dest); err == nil {
+		if !isDir {
+			return fmt.Errorf("destination %s exists and is not a directory", dest)
+		}
+		entries, err := os.ReadDir(dest)
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 && !app.Settings.Overwrite {
+			return fmt.Errorf("destination %s is not empty and `overwrite` is not set", dest)
+		}
+	}
+
+	defer app.cleanupTempDir()
+	globalAuth := app.globalAuth()
+
+	sources, err := yutcTemplate.ResolveTemplatePaths(app.Settings.TemplatePaths, false, app.TempDir, app.Logger)
+	if err != nil {
+		return err
+	}
+	root := sources[0]
+	if isContainer, err := root.IsContainer(); err != nil {
+		return err
+	} else if !isContainer {
+		return fmt.Errorf("project template %s must be a directory, archive or git repository", source)
+	}
+	if !root.Auth.Disabled && root.Auth.BasicAuth == "" && root.Auth.BearerToken == "" {
+		root.Auth = globalAuth
+	}
+	if err = yutcTemplate.MarkCopies([]*yutcTemplate.Input{root}, app.Settings.CopyGlobs, app.Settings.TemplateGlobs); err != nil {
+		return err
+	}
+
+	files := make(map[string]*yutcTemplate.Input)
+	for _, child := range root.AllChildren() {
+		if isDir, err := child.IsDir(); err != nil {
+			return err
+		} else if isDir {
+			continue
+		}
+		rel, err := child.RelativePath()
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = child
+	}
+	manifest := &scaffold.Manifest{}
+	if manifestFile, ok := files[scaffold.ManifestName]; ok {
+		if manifest, err = scaffold.ParseManifest(manifestFile.Content.Data); err != nil {
+			return err
+		}
+	}
+	app.RunData.TemplateFiles = nil
+	for _, rel := range slices.Sorted(maps.Keys(files)) {
+		if manifest.Excluded(rel) {
+			app.Logger.Debug().Msgf("Excluding %s from the project", rel)
+			continue
 		}
+		app.RunData.TemplateFiles = append(app.RunData.TemplateFiles, files[rel])
 	}
 
-	app.RunData.CommonTemplateFiles, err = yutcTemplate.ResolveTemplatePaths(app.Settings.CommonTemplateFiles, true, tempDir, app.Logger)
+	app.RunData.CommonTemplateFiles, err = yutcTemplate.ResolveTemplatePaths(app.Settings.CommonTemplateFiles, true, app.TempDir, app.Logger)
 	if err != nil {
 		return err
 	}
@@ -94,143 +186,803 @@ func (app *App) Run(_ context.Context, args []string) (err error) {
 			cf.Auth = globalAuth
 		}
 	}
-
-	app.RunData.DataFiles, err = data.ResolveDataPaths(app.Settings.DataFiles, tempDir, app.Logger)
+	if err = app.resolveDataFiles(globalAuth); err != nil {
+		return err
+	}
+	schemaInput, err := manifestSchema(manifest, files)
 	if err != nil {
 		return err
 	}
-	for _, df := range app.RunData.DataFiles {
-		if !df.Auth.Disabled && df.Auth.BasicAuth == "" && df.Auth.BearerToken == "" {
-			df.Auth = globalAuth
-		}
+	if schemaInput != nil {
+		schemaInput.SetLogger(app.Logger)
+		schemaInput.Schema.Prompt = prompter()
+		app.RunData.DataFiles = append(app.RunData.DataFiles, schemaInput)
+	}
+	if err = app.mergeData(); err != nil {
+		return err
 	}
 
-	// Filter out common template data from the main template list to avoid duplicate loading
-	// we make assumption that the intention of anything specified as a common template explicitly
-	// will not intend for it to be loaded again or copied even if it was included in the main template paths
-	app.RunData.TemplateFiles = filterCommonTemplateInputs(app.RunData.TemplateFiles, app.RunData.CommonTemplateFiles)
-
-	err = config.ValidateArguments(app.Settings, &config.ParsedInputs{
-		DataFiles:           app.RunData.DataFiles,
-		TemplateFiles:       app.RunData.TemplateFiles,
-		CommonTemplateFiles: app.RunData.CommonTemplateFiles,
-	}, app.Logger)
-	if err != nil {
+	if err = os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	if err = app.renderTemplates(); err != nil {
 		return err
 	}
 
-	app.RunData.MergedData, err = data.MergeDataFiles(app.RunData.DataFiles, app.Settings.SetData, app.Settings.Helm, app.Logger)
+	if len(manifest.Hooks.Post) > 0 {
+		if !app.Settings.AllowShell {
+			app.Logger.Warn().Msgf("Skipping %d post-render hook(s) of %s, use --allow-shell to run them", len(manifest.Hooks.Post), source)
+			return nil
+		}
+		app.Logger.Debug().Msgf("Running %d post-render hook(s) in %s", len(manifest.Hooks.Post), dest)
+		return scaffold.RunHooks(manifest.Hooks.Post, dest, os.Stderr, os.Stderr)
+	}
+	return nil
+}
+
+// manifestSchema returns the schema of a project template's manifest as a schema input, or nil if it has
+// none. files are the files of the project template by their relative path.
+func manifestSchema(manifest *scaffold.Manifest, files map[string]*yutcTemplate.Input) (*data.Input, error) {
+	if schemaPath := manifest.SchemaPath(); schemaPath != "" {
+		schemaFile, ok := files[schemaPath]
+		if !ok {
+			return nil, fmt.Errorf("schema %s of %s not found", schemaPath, scaffold.ManifestName)
+		}
+		return data.NewInput(schemaFile.Name, []loader.FileEntryOption{
+			loader.WithSource(schemaFile.Source),
+			loader.WithContentBytes(schemaFile.Content.Data),
+		}, data.AsSchema()), nil
+	}
+	if manifest.Schema == nil {
+		return nil, nil
+	}
+	schemaBytes, err := json.Marshal(manifest.Schema)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("invalid schema in %s: %w", scaffold.ManifestName, err)
 	}
+	return data.NewInput(files[scaffold.ManifestName].Name, []loader.FileEntryOption{
+		loader.WithSource(files[scaffold.ManifestName].Source),
+		loader.WithContentBytes(schemaBytes),
+	}, data.AsSchema(), data.WithFormat(data.FormatJSON)), nil
+}
 
-	templateSet, err := yutcTemplate.LoadTemplateSet(
-		app.RunData.TemplateFiles,
-		app.RunData.CommonTemplateFiles,
-		app.RunData.MergedData,
-		app.Settings.Strict,
-		app.Settings.IncludeFilenames,
-		app.Settings.DropExtension,
-		app.Settings.AllowShell,
-		app.Logger,
-	)
+// renderTemplates loads the template files against the merged data and writes each rendered template to
+// stdout or its output file. All templates are rendered and every output path is claimed before anything is
+// written, so that a collision leaves the output untouched.
+func (app *App) renderTemplates() (err error) {
+	templateSet, err := app.loadTemplateSet()
 	if err != nil {
 		return err
 	}
 
 	// Execute each template from the shared template object
 	var skip []string
+	var pending []*pendingOutput
+	outputs := make(map[string]string) // the template or file written to each output path
 
-	for _, templateFile := range templateSet.TemplateFiles {
-		templatePath := templateFile.Name // The template name (file path)
-		if templateFile.Template.NewName != "" {
-			templatePath = templateFile.Template.NewName
-		}
-
-		// Compute relative path from the root container if it exists
-		relativePath, err := templateFile.RelativeNewPath()
-		if err != nil {
-			return err
-		}
+	err = app.renderEach(templateSet, func(templateFile *yutcTemplate.Input, rendered *renderedTemplate) error {
+		templatePath := rendered.name
+		relativePath := rendered.relativePath
 
 		var outputPath string
-		if app.Settings.Output != "-" {
-			outputIsDir, err := loader.IsDir(app.Settings.Output)
-			if err != nil {
-				// If output doesn't exist, treat as directory if we have multiple files
-				if len(templateSet.TemplateFiles) > 1 {
-					outputIsDir = true
-				}
+		output, outputIsDir := app.templateOutput(templateSet, templateFile)
+		if output != "-" {
+			if outputIsDir && templateFile.Source == loader.SourceKindStdin && rendered.frontMatterOutput == "" {
+				return fmt.Errorf("the template from stdin has no file name in %s, set its output with out=", output)
 			}
-
 			if outputIsDir {
-				outputPath = loader.NormalizeFilepath(filepath.Join(app.Settings.Output, relativePath))
+				outputPath = loader.NormalizeFilepath(filepath.Join(output, relativePath))
 			} else {
-				outputPath = loader.NormalizeFilepath(app.Settings.Output)
+				outputPath = loader.NormalizeFilepath(output)
 			}
 		}
-		outData := new(bytes.Buffer)
-		// execute the specific named template from the shared template object
 		if slices.Contains(skip, templatePath) {
 			return fmt.Errorf(
 				"template %s was marked to be skipped for processing, but is being preocessed, report a bug ticket please",
 				templatePath,
 			)
 		}
-		err = templateSet.Template.ExecuteTemplate(outData, templatePath, app.RunData.MergedData)
-		if err != nil {
-			return &types.TemplateError{
-				TemplatePath: templatePath,
-				Err:          err,
-			}
-		}
-		outBytes := outData.Bytes()
-		switch app.Settings.Output {
-		case "-":
-			app.Logger.Debug().Msg("Writing to stdout")
-			_, err = os.Stdout.Write(outBytes)
-			if err != nil {
-				return err
-			}
-		default:
-			if app.Settings.IgnoreEmpty && strings.TrimSpace(string(outBytes)) == "" {
+		if output != "-" {
+			if app.Settings.IgnoreEmpty && strings.TrimSpace(string(rendered.output)) == "" {
 				app.Logger.Debug().Msgf("Skipping empty output for template: %s", templatePath)
-				continue
+				return nil
 			}
-			_ = filepath.Dir(outputPath)
 			outputBasename := filepath.Base(outputPath)
 
 			isDir, err := loader.IsDir(outputPath)
 			if err == nil && isDir && len(templateSet.TemplateFiles) == 1 {
 				// behavior for single template file and output is a directory
 				// matches normal behavior expected by commands like cp, mv etc.
-				outputPath = filepath.Join(app.Settings.Output, outputBasename)
+				outputPath = filepath.Join(output, outputBasename)
 				_, err = loader.IsDir(outputPath)
 				if err != nil {
 					return err
 				}
 			}
+			if err = claimOutput(outputs, outputPath, templateFile.Name); err != nil {
+				return err
+			}
+		}
+		pending = append(pending, &pendingOutput{file: templateFile, rendered: rendered, outputPath: outputPath})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, copyFile := range templateSet.CopyFiles {
+		outputPath, err := app.copyOutputPath(copyFile)
+		if err != nil {
+			return err
+		}
+		if outputPath != "" {
+			if err = claimOutput(outputs, outputPath, copyFile.Name); err != nil {
+				return err
+			}
+		}
+		pending = append(pending, &pendingOutput{file: copyFile, outputPath: outputPath})
+	}
 
-			isDir, err = loader.IsDir(outputPath)
-			// the error here is going to be that the file doesn't exist
-			if err != nil || (!isDir && app.Settings.Overwrite) {
-				if app.Settings.Overwrite {
-					app.Logger.Debug().Msg("Overwrite enabled, writing to file(s): " + app.Settings.Output)
-				}
-				err = os.MkdirAll(filepath.Dir(outputPath), 0o755)
-				if err != nil {
-					return err
-				}
-				err = os.WriteFile(outputPath, outBytes, 0o644)
-				if err != nil {
-					return err
-				}
-			} else {
-				app.Logger.Error().Msg("file exists and overwrite is not set: " + outputPath)
+	for _, p := range pending {
+		if p.rendered == nil {
+			err = app.copyFile(p.file, p.outputPath)
+		} else {
+			err = app.writeRendered(p.file, p.rendered, p.outputPath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingOutput is a rendered template, or a file copied as is if rendered is nil, waiting to be written to
+// outputPath, "" for stdout.
+type pendingOutput struct {
+	file       *yutcTemplate.Input
+	rendered   *renderedTemplate
+	outputPath string
+}
+
+// writeRendered writes a rendered template to stdout if outputPath is "", or else to outputPath with the mode
+// and overwrite of its front matter.
+func (app *App) writeRendered(templateFile *yutcTemplate.Input, rendered *renderedTemplate, outputPath string) error {
+	if outputPath == "" {
+		app.Logger.Debug().Msg("Writing to stdout")
+		_, err := os.Stdout.Write(rendered.output)
+		return err
+	}
+	frontMatter := templateFile.Template.FrontMatter
+	overwrite := app.Settings.Overwrite
+	if frontMatter != nil && frontMatter.Overwrite != nil {
+		overwrite = *frontMatter.Overwrite
+	}
+	isDir, err := loader.IsDir(outputPath)
+	// the error here is going to be that the file doesn't exist
+	if err == nil && (isDir || !overwrite) {
+		app.Logger.Error().Msg("file exists and overwrite is not set: " + outputPath)
+		return nil
+	}
+	if overwrite {
+		app.Logger.Debug().Msg("Overwrite enabled, writing to file(s): " + outputPath)
+	}
+	if err = os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	if err = os.WriteFile(outputPath, rendered.output, 0o644); err != nil {
+		return err
+	}
+	mode := templateFile.ArgMode()
+	if frontMatter != nil && frontMatter.Mode != 0 {
+		mode = frontMatter.Mode
+	}
+	if mode != 0 {
+		// WriteFile only sets the mode of new files, and subject to the umask
+		if err = os.Chmod(outputPath, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderedTemplate is a template of a set executed with its data.
+type renderedTemplate struct {
+	name              string // the name of the template in the set
+	relativePath      string // the path of its output in an output directory
+	frontMatterOutput string // the output set by its front matter, if any
+	output            []byte
+}
+
+// renderEach executes each template of templateSet with the merged data, scoped to its jsonpath= and with the
+// defaults of its front matter, and passes it to write. Templates whose front matter says to skip them are not
+// executed.
+func (app *App) renderEach(templateSet *yutcTemplate.TemplateSet, write func(*yutcTemplate.Input, *renderedTemplate) error) error {
+	for _, templateFile := range templateSet.TemplateFiles {
+		rendered := &renderedTemplate{name: templateFile.Name}
+		if templateFile.Template.NewName != "" {
+			rendered.name = templateFile.Template.NewName
+		}
+
+		scopedData, err := templateFile.ScopeData(app.RunData.MergedData)
+		if err != nil {
+			return err
+		}
+		templateData := templateFile.Template.FrontMatter.WithDefaults(scopedData)
+		frontMatterOutput, skipped, err := templateSet.RenderFrontMatter(templateFile, templateData)
+		if err != nil {
+			return err
+		}
+		if skipped {
+			app.Logger.Debug().Msgf("Skipping template %s as its front matter says to", rendered.name)
+			continue
+		}
+
+		// Compute relative path from the root container if it exists
+		if rendered.relativePath, err = templateFile.RelativeNewPath(); err != nil {
+			return err
+		}
+		if frontMatterOutput != "" {
+			if rendered.relativePath, err = frontMatterPath(frontMatterOutput); err != nil {
+				return fmt.Errorf("invalid front matter output of %s: %w", templateFile.Name, err)
+			}
+		}
+		rendered.frontMatterOutput = frontMatterOutput
+
+		if rendered.output, err = app.executeTemplate(templateSet, rendered.name, templateData); err != nil {
+			return err
+		}
+		if err = write(templateFile, rendered); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateOutput returns where templateFile is written: the out= of its template argument, or else --output, - for
+// stdout, and whether it is a directory the file is written to at its relative path, as the output of a container
+// always is.
+func (app *App) templateOutput(templateSet *yutcTemplate.TemplateSet, templateFile *yutcTemplate.Input) (string, bool) {
+	if output := templateFile.ArgOutput(); output != "" {
+		if templateFile.Container.Root != nil {
+			return output, true
+		}
+		isDir, err := loader.IsDir(output)
+		return output, err == nil && isDir
+	}
+	if app.Settings.Output == "-" {
+		return "-", false
+	}
+	outputIsDir, err := loader.IsDir(app.Settings.Output)
+	if err != nil {
+		// If output doesn't exist, treat as directory if we have multiple files
+		if len(templateSet.TemplateFiles)+len(templateSet.CopyFiles)+len(templateSet.Partials) > 1 {
+			outputIsDir = true
+		}
+	}
+	return app.Settings.Output, outputIsDir
+}
+
+// copyOutputPath returns the path in the output directory a file of a template container is copied to, "" if the
+// output is stdout.
+func (app *App) copyOutputPath(copyFile *yutcTemplate.Input) (string, error) {
+	output := app.Settings.Output
+	if argOutput := copyFile.ArgOutput(); argOutput != "" {
+		output = argOutput
+	}
+	if output == "-" {
+		return "", nil
+	}
+	relativePath, err := copyFile.RelativeNewPath()
+	if err != nil {
+		return "", err
+	}
+	return loader.NormalizeFilepath(filepath.Join(output, relativePath)), nil
+}
+
+// copyFile writes a file of a template container as is to outputPath, its place in the output directory, with its
+// mode. Files are not copied to stdout, outputPath "".
+func (app *App) copyFile(copyFile *yutcTemplate.Input, outputPath string) error {
+	if outputPath == "" {
+		app.Logger.Warn().Msgf("Not copying %s to stdout, use --output to copy it to a directory", copyFile.Name)
+		return nil
+	}
+	if exists, err := loader.Exists(outputPath); err != nil {
+		return err
+	} else if exists && !app.Settings.Overwrite {
+		app.Logger.Error().Msg("file exists and overwrite is not set: " + outputPath)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	app.Logger.Debug().Msgf("Copying %s to %s", copyFile.Name, outputPath)
+	mode := copyFile.Content.Mode
+	if argMode := copyFile.ArgMode(); argMode != 0 {
+		mode = os.FileMode(argMode)
+	} else if mode == 0 {
+		mode = 0o644
+	}
+	if err := os.WriteFile(outputPath, copyFile.Content.Data, mode); err != nil {
+		return err
+	}
+	return os.Chmod(outputPath, mode)
+}
+
+// claimOutput records that the template or file name is written to outputPath, in outputs, and returns an error
+// if another one already is, as when templated names render to the same path.
+func claimOutput(outputs map[string]string, outputPath, name string) error {
+	if other, ok := outputs[outputPath]; ok {
+		return fmt.Errorf("%s and %s both render to %s", other, name, outputPath)
+	}
+	outputs[outputPath] = name
+	return nil
+}
+
+// loadTemplateSet parses the template and common template files of RunData against the merged data, instrumented
+// to record their coverage with --coverage.
+func (app *App) loadTemplateSet() (*yutcTemplate.TemplateSet, error) {
+	templateSet, err := yutcTemplate.LoadTemplateSet(
+		app.RunData.TemplateFiles,
+		app.RunData.CommonTemplateFiles,
+		app.RunData.MergedData,
+		app.Settings.Strict,
+		app.Settings.IncludeFilenames,
+		app.Settings.DropExtension,
+		app.Settings.AllowShell,
+		app.Logger,
+	)
+	if err != nil || app.coverage == nil {
+		return templateSet, err
+	}
+	return templateSet, yutcTemplate.Instrument(templateSet, app.coverage)
+}
+
+// writeCoverage writes the coverage report to the --coverage file, as HTML if its extension is .html or .htm
+// and as text otherwise, replacing any previous report.
+func (app *App) writeCoverage() error {
+	if app.coverage == nil {
+		return nil
+	}
+	var out bytes.Buffer
+	var err error
+	switch strings.ToLower(filepath.Ext(app.Settings.Coverage)) {
+	case ".html", ".htm":
+		err = coverage.WriteHTML(&out, app.coverage)
+	default:
+		err = coverage.WriteText(&out, app.coverage)
+	}
+	if err != nil {
+		return err
+	}
+	path := loader.NormalizeFilepath(app.Settings.Coverage)
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	app.Logger.Debug().Msg("Writing coverage report to " + path)
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// executeTemplate renders the named template of the set with data, the merged data with the defaults of the
+// template's front matter.
+func (app *App) executeTemplate(templateSet *yutcTemplate.TemplateSet, name string, data map[string]any) ([]byte, error) {
+	outData := new(bytes.Buffer)
+	if err := templateSet.Template.ExecuteTemplate(outData, name, data); err != nil {
+		return nil, templateSet.Diagnose(err, name)
+	}
+	return outData.Bytes(), nil
+}
+
+// frontMatterPath returns the output path set by a front matter, which must be relative and within the output
+// directory.
+func frontMatterPath(output string) (string, error) {
+	relativePath := filepath.FromSlash(output)
+	if !filepath.IsLocal(relativePath) {
+		return "", fmt.Errorf("%q must be a relative path within the output directory", output)
+	}
+	return filepath.Clean(relativePath), nil
+}
+
+// DumpData merges the data inputs without loading any templates and writes the merged result
+// to the configured output, optionally narrowed to the node(s) selected by a JSONPath.
+func (app *App) DumpData(_ context.Context) (err error) {
+	if app.Logger.GetLevel() < zerolog.DebugLevel {
+		app.LogSettings()
+	}
+
+	if app.Settings.Version {
+		PrintVersion()
+		return nil
+	}
+
+	format := data.FormatYAML
+	if app.Settings.DataFormat != "" {
+		format, err = data.ParseOutputFormat(app.Settings.DataFormat)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer app.cleanupTempDir()
+
+	err = app.resolveDataFiles(app.globalAuth())
+	if err != nil {
+		return err
+	}
+
+	err = config.ValidateArguments(app.Settings, &config.ParsedInputs{
+		DataFiles: app.RunData.DataFiles,
+	}, app.Logger)
+	if err != nil {
+		return err
+	}
+
+	if err = app.mergeData(); err != nil {
+		return err
+	}
+
+	var out any = app.RunData.MergedData
+	if app.Settings.DataJSONPath != "" {
+		out, err = yutcTemplate.JSONPathQuery(app.RunData.MergedData, app.Settings.DataJSONPath)
+		if err != nil {
+			return fmt.Errorf("unable to query merged data with %s: %w", app.Settings.DataJSONPath, err)
+		}
+	}
+
+	outBytes, err := data.Marshal(out, format)
+	if err != nil {
+		return err
+	}
+	return app.writeOutput(outBytes)
+}
+
+// InferSchema parses the templates, without any data, and writes a skeleton JSON Schema of the data
+// they use to the configured output.
+func (app *App) InferSchema(_ context.Context, args []string) (err error) {
+	app.Settings.TemplatePaths = args
+	if app.Logger.GetLevel() < zerolog.DebugLevel {
+		app.LogSettings()
+	}
+
+	format := data.FormatJSON
+	if app.Settings.SchemaFormat != "" {
+		format, err = data.ParseOutputFormat(app.Settings.SchemaFormat)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer app.cleanupTempDir()
+
+	if err = app.resolveTemplateFiles(app.globalAuth()); err != nil {
+		return err
+	}
+
+	templateSet, err := yutcTemplate.LoadTemplateSet(
+		app.RunData.TemplateFiles,
+		app.RunData.CommonTemplateFiles,
+		nil,
+		false,
+		false,
+		app.Settings.DropExtension,
+		false,
+		app.Logger,
+	)
+	if err != nil {
+		return err
+	}
+
+	var out map[string]any
+	schemaBytes, err := json.Marshal(yutcTemplate.InferSchema(templateSet))
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(schemaBytes, &out); err != nil {
+		return err
+	}
+	outBytes, err := data.Marshal(out, format)
+	if err != nil {
+		return err
+	}
+	return app.writeOutput(outBytes)
+}
+
+// Lint parses the templates, without rendering them, and writes the problems found to the configured output
+// as text or SARIF. Data keys are checked against the merged data and schemas when any data is given. It
+// returns a *types.ExitError if any problem is an error.
+func (app *App) Lint(_ context.Context, args []string) (err error) {
+	app.Settings.TemplatePaths = args
+	if app.Logger.GetLevel() < zerolog.DebugLevel {
+		app.LogSettings()
+	}
+
+	switch app.Settings.LintFormat {
+	case "", "text", "sarif":
+	default:
+		return fmt.Errorf("invalid lint format %q: must be one of: text, sarif", app.Settings.LintFormat)
+	}
+
+	defer app.cleanupTempDir()
+	globalAuth := app.globalAuth()
+
+	if err = app.resolveTemplateFiles(globalAuth); err != nil {
+		return err
+	}
+	if err = app.resolveDataFiles(globalAuth); err != nil {
+		return err
+	}
+	hasData, err := app.dataChecker()
+	if err != nil {
+		return err
+	}
+
+	var findings []lint.Finding
+	// templates are parsed with shell available, so its use is reported rather than failing to parse
+	templateSet, err := yutcTemplate.LoadTemplateSet(
+		app.RunData.TemplateFiles,
+		app.RunData.CommonTemplateFiles,
+		nil,
+		false,
+		false,
+		app.Settings.DropExtension,
+		true,
+		app.Logger,
+	)
+	var templateErr *types.TemplateError
+	if errors.As(err, &templateErr) {
+		findings = append(findings, lint.Finding{
+			Rule:    lint.RuleParseError,
+			Path:    templateErr.TemplatePath,
+			Line:    templateErr.Line,
+			Column:  templateErr.Column,
+			Message: templateErr.Message,
+		})
+	} else if err != nil {
+		return err
+	} else {
+		findings = yutcTemplate.Lint(templateSet, yutcTemplate.LintOptions{
+			AllowShell: app.Settings.AllowShell,
+			HasData:    hasData,
+		})
+	}
+
+	var out bytes.Buffer
+	if app.Settings.LintFormat == "sarif" {
+		err = lint.WriteSARIF(&out, findings, GetVersion())
+	} else {
+		err = lint.WriteText(&out, findings)
+	}
+	if err != nil {
+		return err
+	}
+	if err = app.writeOutput(out.Bytes()); err != nil {
+		return err
+	}
+	if lint.HasErrors(findings) {
+		return &types.ExitError{Code: 1, Err: fmt.Errorf("lint found %d problem(s)", len(findings))}
+	}
+	return nil
+}
+
+// dataChecker merges the data inputs and returns a function reporting whether a path of the data, made of keys
+// and schema.ItemSegment, is in the merged data or declared by a kind=schema input, or nil if no data is given.
+func (app *App) dataChecker() (func(path []string) bool, error) {
+	if len(app.RunData.DataFiles) == 0 && len(app.setArgs()) == 0 {
+		return nil, nil
+	}
+	if err := app.mergeData(); err != nil {
+		return nil, err
+	}
+	type rootedSchema struct {
+		schema *schema.Compiled
+		root   []string
+	}
+	var schemas []rootedSchema
+	for _, df := range app.RunData.DataFiles {
+		if !df.IsSchema {
+			continue
+		}
+		compiled, err := df.CompileSchema()
+		if err != nil {
+			return nil, err
+		}
+		if root, found := df.SchemaRoot(app.RunData.MergedData); found {
+			schemas = append(schemas, rootedSchema{schema: compiled, root: root})
+		}
+	}
+	return func(path []string) bool {
+		if dataHasPath(app.RunData.MergedData, path) {
+			return true
+		}
+		for _, s := range schemas {
+			if len(path) < len(s.root) {
+				continue
+			}
+			if slices.EqualFunc(path[:len(s.root)], s.root, func(a, b string) bool {
+				return a == b || a == schema.ItemSegment || b == schema.ItemSegment
+			}) && s.schema.Declares(path[len(s.root):]) {
+				return true
 			}
 		}
+		return false
+	}, nil
+}
+
+// dataHasPath reports whether the path, made of keys and schema.ItemSegment, is in v. An ItemSegment matches if
+// any element has the rest of the path, or the list or map is empty, as its elements are unknown.
+func dataHasPath(v any, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	switch value := v.(type) {
+	case map[string]any:
+		if path[0] != schema.ItemSegment {
+			child, ok := value[path[0]]
+			return ok && dataHasPath(child, path[1:])
+		}
+		if len(value) == 0 {
+			return true
+		}
+		for _, child := range value {
+			if dataHasPath(child, path[1:]) {
+				return true
+			}
+		}
+	case []any:
+		if path[0] != schema.ItemSegment {
+			return false
+		}
+		if len(value) == 0 {
+			return true
+		}
+		for _, child := range value {
+			if dataHasPath(child, path[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeOutput writes a single output to stdout or the configured output file.
+func (app *App) writeOutput(outBytes []byte) error {
+	if app.Settings.Output == "-" {
+		app.Logger.Debug().Msg("Writing to stdout")
+		_, err := os.Stdout.Write(outBytes)
+		return err
+	}
+	outputPath := loader.NormalizeFilepath(app.Settings.Output)
+	if exists, err := loader.Exists(outputPath); err != nil {
+		return err
+	} else if exists {
+		if isDir, _ := loader.IsDir(outputPath); isDir {
+			return fmt.Errorf("output %s is a directory", outputPath)
+		}
+		if !app.Settings.Overwrite {
+			return errors.New("file " + outputPath + " exists and `overwrite` is not set")
+		}
+	}
+	err := os.MkdirAll(filepath.Dir(outputPath), 0o755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, outBytes, 0o644)
+}
+
+// cleanupTempDir removes the temp directory used for processing (git checkouts etc.) if it was created.
+func (app *App) cleanupTempDir() {
+	if exists, err := loader.Exists(app.TempDir); exists {
+		if err != nil {
+			app.Logger.Error().Err(err).Msg("failed to check if temp directory exists")
+		}
+		_ = os.RemoveAll(app.TempDir)
+	}
+}
+
+// globalAuth returns the auth configured with --auth, which is applied lazily to any URL
+// input that does not specify its own.
+func (app *App) globalAuth() loader.AuthInfo {
+	globalAuth := loader.ParseAuthString(app.Settings.Auth)
+	if globalAuth.BasicAuth != "" || globalAuth.BearerToken != "" {
+		globalAuth.Lazy = true
+	}
+	return globalAuth
+}
+
+// resolveTemplateFiles parses and loads the template and common template inputs into RunData, applying the
+// global auth where unset.
+func (app *App) resolveTemplateFiles(globalAuth loader.AuthInfo) (err error) {
+	app.RunData.TemplateFiles, err = yutcTemplate.ResolveTemplatePathsRelativeTo(app.Settings.TemplatePaths, false, app.baseDir, app.TempDir, app.Logger)
+	if err != nil {
+		return err
+	}
+	app.RunData.CommonTemplateFiles, err = yutcTemplate.ResolveTemplatePathsRelativeTo(app.Settings.CommonTemplateFiles, true, app.baseDir, app.TempDir, app.Logger)
+	if err != nil {
+		return err
+	}
+	for _, tf := range slices.Concat(app.RunData.TemplateFiles, app.RunData.CommonTemplateFiles) {
+		if !tf.Auth.Disabled && tf.Auth.BasicAuth == "" && tf.Auth.BearerToken == "" {
+			tf.Auth = globalAuth
+		}
+	}
+
+	// Filter out common template data from the main template list to avoid duplicate loading
+	// we make assumption that the intention of anything specified as a common template explicitly
+	// will not intend for it to be loaded again or copied even if it was included in the main template paths
+	app.RunData.TemplateFiles = filterCommonTemplateInputs(app.RunData.TemplateFiles, app.RunData.CommonTemplateFiles)
+	return yutcTemplate.MarkCopies(app.RunData.TemplateFiles, app.Settings.CopyGlobs, app.Settings.TemplateGlobs)
+}
+
+// resolveDataFiles parses and loads the --data inputs into RunData, applying the global auth where unset
+// and the --strict and --allow-shell settings to data templates.
+func (app *App) resolveDataFiles(globalAuth loader.AuthInfo) (err error) {
+	app.RunData.DataFiles, err = data.ResolveDataPathsRelativeTo(app.Settings.DataFiles, app.baseDir, app.TempDir, app.Logger)
+	if err != nil {
+		return err
+	}
+	var prompt data.PromptFunc
+	if app.Settings.Prompt {
+		prompt = prompter()
+	}
+	for _, df := range app.RunData.DataFiles {
+		if !df.Auth.Disabled && df.Auth.BasicAuth == "" && df.Auth.BearerToken == "" {
+			df.Auth = globalAuth
+		}
+		if df.IsTemplate {
+			df.Template = data.TemplateInfo{Strict: app.Settings.Strict, AllowShell: app.Settings.AllowShell}
+		}
+		if df.IsSchema && app.Settings.Prompt {
+			df.Schema.Prompt = prompt
+		}
+	}
+	return nil
+}
+
+// prompter returns the PromptFunc for --prompt, which asks on the terminal when stdin is one and otherwise
+// fails with the missing values.
+func prompter() data.PromptFunc {
+	if isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return data.TerminalPrompt(os.Stdin, os.Stderr)
+	}
+	return data.NoPrompt
+}
+
+// setArgs returns the --set style flags in the order they are applied: --set-json, --set, --set-string,
+// --set-file and finally --unset, following helm's order.
+func (app *App) setArgs() []data.SetArg {
+	var setArgs []data.SetArg
+	setArgs = append(setArgs, data.NewSetArgs(data.SetKindJSON, app.Settings.SetJSON)...)
+	setArgs = append(setArgs, data.NewSetArgs(data.SetKindValue, app.Settings.SetData)...)
+	setArgs = append(setArgs, data.NewSetArgs(data.SetKindString, app.Settings.SetString)...)
+	setArgs = append(setArgs, data.NewSetArgs(data.SetKindFile, app.Settings.SetFile)...)
+	return append(setArgs, data.NewSetArgs(data.SetKindUnset, app.Settings.Unset)...)
+}
+
+// mergeData merges all data inputs into RunData.MergedData, resolves references if enabled, and logs the result, with secrets redacted, at TRACE level.
+func (app *App) mergeData() (err error) {
+	setArgs := app.setArgs()
+	app.RunData.MergedData, err = data.MergeDataFiles(app.RunData.DataFiles, setArgs, app.Settings.Helm, app.Logger)
+	if err != nil {
+		return err
+	}
+	if app.Settings.ResolveRefs {
+		if err = data.ResolveRefs(app.RunData.MergedData, app.RunData.DataFiles, setArgs); err != nil {
+			return err
+		}
+	}
+	app.RunData.SecretPaths = nil
+	for _, df := range app.RunData.DataFiles {
+		app.RunData.SecretPaths = append(app.RunData.SecretPaths, df.SecretPaths...)
+	}
+	if app.Logger.Trace().Enabled() {
+		yamlData, err := yaml.Marshal(data.RedactSecrets(app.RunData.MergedData, app.RunData.SecretPaths))
+		if err != nil {
+			return err
+		}
+		app.Logger.Trace().Msg("Merged data:")
+		for _, line := range bytes.Split(yamlData, []byte("\n")) {
+			app.Logger.Trace().Msg("  " + string(line))
+		}
 	}
-	return err
+	return nil
 }
 
 // LogSettings logs the current application settings as YAML at TRACE level.
@@ -272,4 +1024,5 @@ type RunData struct {
 	CommonTemplateFiles []*yutcTemplate.Input
 	TemplateFiles       []*yutcTemplate.Input
 	MergedData          map[string]any
+	SecretPaths         []spec.NormalizedPath // locations of decrypted secret values in MergedData
 }
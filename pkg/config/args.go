@@ -35,7 +35,7 @@ func ValidateArguments(arguments *types.Arguments, parsed *ParsedInputs, logger
 	// - min required args
 	// - general type validation
 	// - mutually exclusive flags (sometimes, i may handle them here for better error logging)
-	errs = validateOutput(arguments, errs, logger)
+	errs = validateOutput(arguments, parsed, errs, logger)
 	errs = validateStructuredInput(arguments, parsed, errs)
 	errs = validateStdin(parsed, errs)
 	errs = verifyMutuallyExclusives(arguments, errs)
@@ -107,14 +107,27 @@ func validateStdin(parsed *ParsedInputs, errs []error) []error {
 }
 
 // validateOutput checks if the output file exists and if it should be overwritten
-func validateOutput(args *types.Arguments, errs []error, logger *zerolog.Logger) []error {
+func validateOutput(args *types.Arguments, parsed *ParsedInputs, errs []error, logger *zerolog.Logger) []error {
 	var err error
 	outputFiles := args.Output != "-"
 	if args.Overwrite && !outputFiles {
 		err = errors.New("cannot use `overwrite` with `stdout`")
 		errs = append(errs, err)
 	}
-	if !outputFiles && len(args.TemplatePaths) > 1 {
+	// templates with an out= are written there whatever the output
+	stdoutTemplates := 0
+	if !outputFiles {
+		stdoutTemplates = len(args.TemplatePaths)
+	}
+	for _, ti := range parsed.TemplateFiles {
+		switch {
+		case ti.Arg.Output == "-" && outputFiles:
+			stdoutTemplates++
+		case ti.Arg.Output != "" && ti.Arg.Output != "-" && !outputFiles:
+			stdoutTemplates--
+		}
+	}
+	if stdoutTemplates > 1 {
 		err = errors.New("cannot use `stdout` with multiple template data flag")
 		errs = append(errs, err)
 	}
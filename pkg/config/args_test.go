@@ -134,4 +134,15 @@ func TestValidateArguments(t *testing.T) {
 	}
 	err = ValidateArguments(args, parsedFromArgs(t, args), &logger)
 	assert.NoError(t, err, "valid with recursable template paths")
+
+	args = &types.Arguments{
+		DataFiles: []string{"../../testFiles/data/data1.yaml"},
+		TemplatePaths: []string{
+			"../../testFiles/templates/template1.tmpl",
+			"src=../../testFiles/templates/template2.tmpl,out=template2.yaml",
+		},
+		Output: "-",
+	}
+	err = ValidateArguments(args, parsedFromArgs(t, args), &logger)
+	assert.NoError(t, err, "only one template is written to stdout, the other to its out=")
 }
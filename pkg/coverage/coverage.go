@@ -0,0 +1,120 @@
+// Package coverage records which blocks of templates were executed and reports it as text or HTML.
+package coverage
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Block is a part of a template executed as a whole: the body of a template file or define, or a branch of an
+// if, range or with.
+type Block struct {
+	Path   string // path of the template file
+	Line   int    // from 1, of the action starting the block
+	Column int    // from 1
+	Label  string // describes the block, ex: `if .Values.enabled`, `else of range .items`
+	Lines  []int  // the lines of the template file whose output is the block's own
+	Count  int    // the number of times the block was executed
+}
+
+// Profile is the coverage of the blocks of template files, collected over any number of renders.
+type Profile struct {
+	blocks  []*Block
+	byKey   map[string]*Block
+	sources map[string]string // text of the template files by path
+}
+
+// NewProfile returns an empty profile.
+func NewProfile() *Profile {
+	return &Profile{byKey: make(map[string]*Block), sources: make(map[string]string)}
+}
+
+// AddSource records the text of the template file at path, for the HTML report.
+func (p *Profile) AddSource(path, text string) {
+	p.sources[path] = text
+}
+
+// Block returns the block of the profile at the location of b, adding b if there is none, so blocks of the same
+// template file parsed by several renders are counted together.
+func (p *Profile) Block(b *Block) *Block {
+	key := fmt.Sprintf("%s:%d:%d:%s", b.Path, b.Line, b.Column, b.Label)
+	if existing, ok := p.byKey[key]; ok {
+		return existing
+	}
+	p.byKey[key] = b
+	p.blocks = append(p.blocks, b)
+	return b
+}
+
+// File is the coverage of a template file.
+type File struct {
+	Path     string
+	Blocks   []*Block // ordered by location
+	Executed int      // the number of blocks executed at least once
+}
+
+// Percent returns the percentage of blocks of the file that were executed.
+func (f *File) Percent() float64 {
+	return percent(f.Executed, len(f.Blocks))
+}
+
+// Files returns the coverage of each template file, ordered by path.
+func (p *Profile) Files() []*File {
+	byPath := make(map[string]*File)
+	var files []*File
+	for _, b := range p.blocks {
+		f, ok := byPath[b.Path]
+		if !ok {
+			f = &File{Path: b.Path}
+			byPath[b.Path] = f
+			files = append(files, f)
+		}
+		f.Blocks = append(f.Blocks, b)
+		if b.Count > 0 {
+			f.Executed++
+		}
+	}
+	for _, f := range files {
+		slices.SortStableFunc(f.Blocks, func(a, b *Block) int {
+			return cmp.Or(cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+		})
+	}
+	slices.SortFunc(files, func(a, b *File) int { return cmp.Compare(a.Path, b.Path) })
+	return files
+}
+
+// Totals returns the number of blocks executed at least once and of blocks.
+func (p *Profile) Totals() (executed, blocks int) {
+	for _, b := range p.blocks {
+		if b.Count > 0 {
+			executed++
+		}
+	}
+	return executed, len(p.blocks)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// WriteText writes the coverage of each template file followed by its blocks and the number of times they were
+// executed, ex: `  12:3  0  if .Values.enabled`.
+func WriteText(w io.Writer, p *Profile) error {
+	var b strings.Builder
+	for _, f := range p.Files() {
+		fmt.Fprintf(&b, "%s: %d of %d blocks executed (%.1f%%)\n", f.Path, f.Executed, len(f.Blocks), f.Percent())
+		for _, block := range f.Blocks {
+			fmt.Fprintf(&b, "  %-8s %5d  %s\n", fmt.Sprintf("%d:%d", block.Line, block.Column), block.Count, block.Label)
+		}
+	}
+	executed, blocks := p.Totals()
+	fmt.Fprintf(&b, "total: %d of %d blocks executed (%.1f%%)\n", executed, blocks, percent(executed, blocks))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,55 @@
+package coverage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProfile() *Profile {
+	p := NewProfile()
+	p.AddSource("b.tmpl", "{{ if .a }}\na: <yes>\n{{ else }}\nno\n{{ end }}\n")
+	p.AddSource("a.tmpl", "x\n")
+	p.Block(&Block{Path: "b.tmpl", Line: 1, Column: 1, Label: "template", Lines: []int{1}}).Count = 2
+	p.Block(&Block{Path: "b.tmpl", Line: 3, Column: 1, Label: "else of if .a", Lines: []int{4}})
+	p.Block(&Block{Path: "b.tmpl", Line: 1, Column: 1, Label: "if .a", Lines: []int{2}}).Count = 2
+	p.Block(&Block{Path: "a.tmpl", Line: 1, Column: 1, Label: "template", Lines: []int{1}}).Count = 1
+	return p
+}
+
+func TestProfile_Block(t *testing.T) {
+	p := testProfile()
+	block := p.Block(&Block{Path: "b.tmpl", Line: 1, Column: 1, Label: "if .a"})
+	assert.Equal(t, 2, block.Count, "blocks at the same location are the same block")
+	assert.Equal(t, []int{2}, block.Lines)
+
+	executed, blocks := p.Totals()
+	assert.Equal(t, 3, executed)
+	assert.Equal(t, 4, blocks)
+}
+
+func TestWriteText(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteText(&out, testProfile()))
+	assert.Equal(t, `a.tmpl: 1 of 1 blocks executed (100.0%)
+  1:1          1  template
+b.tmpl: 2 of 3 blocks executed (66.7%)
+  1:1          2  template
+  1:1          2  if .a
+  3:1          0  else of if .a
+total: 3 of 4 blocks executed (75.0%)
+`, out.String())
+}
+
+func TestWriteHTML(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteHTML(&out, testProfile()))
+	html := out.String()
+	assert.Contains(t, html, "<h1>Template coverage: 75.0% of 4 blocks executed</h1>")
+	assert.Contains(t, html, `<li><a href="#file1">b.tmpl</a>: 66.7% (2 of 3 blocks)</li>`)
+	assert.Contains(t, html, `<tr class="executed"><td class="line">2</td><td class="count">2</td><td class="text">a: &lt;yes&gt;</td></tr>`)
+	assert.Contains(t, html, `<tr class="not-executed"><td class="line">4</td><td class="count">0</td><td class="text">no</td></tr>`)
+	assert.Contains(t, html, `<tr class=""><td class="line">5</td><td class="count"></td><td class="text">{{ end }}</td></tr>`)
+}
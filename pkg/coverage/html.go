@@ -0,0 +1,116 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var htmlReport = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>yutc template coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { margin: 0; }
+table.source { border-collapse: collapse; font-family: monospace; width: 100%; }
+table.source td { padding: 0 0.5em; white-space: pre; vertical-align: top; }
+td.line { color: #888; text-align: right; user-select: none; width: 1%; }
+td.count { color: #888; text-align: right; width: 1%; }
+tr.executed td.text { background: #d7f5d7; }
+tr.not-executed td.text { background: #f9d0d0; }
+tr.partial td.text { background: #fbefc4; }
+</style>
+</head>
+<body>
+<h1>Template coverage: {{ printf "%.1f" .Percent }}% of {{ .Blocks }} blocks executed</h1>
+<ul>
+{{- range .Files }}
+<li><a href="#{{ .ID }}">{{ .Path }}</a>: {{ printf "%.1f" .Percent }}% ({{ .Executed }} of {{ .Blocks }} blocks)</li>
+{{- end }}
+</ul>
+{{- range .Files }}
+<h2 id="{{ .ID }}">{{ .Path }}: {{ printf "%.1f" .Percent }}%</h2>
+<table class="source">
+{{- range .Lines }}
+<tr class="{{ .Class }}"><td class="line">{{ .Number }}</td><td class="count">{{ .Count }}</td><td class="text">{{ .Text }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+type (
+	htmlData struct {
+		Percent float64
+		Blocks  int
+		Files   []htmlFile
+	}
+	htmlFile struct {
+		ID       string
+		Path     string
+		Percent  float64
+		Executed int
+		Blocks   int
+		Lines    []htmlLine
+	}
+	htmlLine struct {
+		Number int
+		Text   string
+		Class  string // executed, not-executed or partial, empty for lines outside any block
+		Count  string // the times the line's blocks were executed
+	}
+)
+
+// WriteHTML writes a page showing the source of each template file with the lines of executed blocks in green,
+// of blocks never executed in red, and of both in yellow.
+func WriteHTML(w io.Writer, p *Profile) error {
+	executed, blocks := p.Totals()
+	data := htmlData{Percent: percent(executed, blocks), Blocks: blocks}
+	for i, f := range p.Files() {
+		file := htmlFile{
+			ID:       fmt.Sprintf("file%d", i),
+			Path:     f.Path,
+			Percent:  f.Percent(),
+			Executed: f.Executed,
+			Blocks:   len(f.Blocks),
+		}
+		type lineCoverage struct{ executed, notExecuted, count int }
+		lines := make(map[int]*lineCoverage)
+		for _, b := range f.Blocks {
+			for _, line := range b.Lines {
+				lc, ok := lines[line]
+				if !ok {
+					lc = &lineCoverage{}
+					lines[line] = lc
+				}
+				if b.Count > 0 {
+					lc.executed++
+					lc.count = max(lc.count, b.Count)
+				} else {
+					lc.notExecuted++
+				}
+			}
+		}
+		for i, text := range strings.Split(strings.TrimSuffix(p.sources[f.Path], "\n"), "\n") {
+			line := htmlLine{Number: i + 1, Text: strings.TrimSuffix(text, "\r")}
+			if lc, ok := lines[i+1]; ok {
+				switch {
+				case lc.notExecuted == 0:
+					line.Class = "executed"
+				case lc.executed == 0:
+					line.Class = "not-executed"
+				default:
+					line.Class = "partial"
+				}
+				line.Count = fmt.Sprint(lc.count)
+			}
+			file.Lines = append(file.Lines, line)
+		}
+		data.Files = append(data.Files, file)
+	}
+	return htmlReport.Execute(w, data)
+}
@@ -14,21 +14,64 @@ func applyDataKindOptions(di *Input, kind *lexer.KindField) error {
 		return nil
 	}
 
-	if kind.Value != "schema" {
-		return fmt.Errorf("invalid kind %q: only 'schema' is supported", kind.Value)
-	}
-
-	di.IsSchema = true
-
-	for argName, argValue := range kind.Args {
-		if argName != "defaults" {
-			return fmt.Errorf("invalid argument %q for kind=schema(): only 'defaults' is allowed", argName)
+	switch kind.Value {
+	case "schema":
+		di.IsSchema = true
+		for argName, argValue := range kind.Args {
+			if argName != "defaults" {
+				return fmt.Errorf("invalid argument %q for kind=schema(): only 'defaults' is allowed", argName)
+			}
+			applyDefaults, err := strconv.ParseBool(argValue)
+			if err != nil {
+				return fmt.Errorf("invalid value for 'defaults' argument: must be 'true' or 'false'")
+			}
+			di.Schema.DisableDefaults = !applyDefaults
 		}
-		applyDefaults, err := strconv.ParseBool(argValue)
-		if err != nil {
-			return fmt.Errorf("invalid value for 'defaults' argument: must be 'true' or 'false'")
+	case "data":
+		for argName, argValue := range kind.Args {
+			switch argName {
+			case "format":
+				format, err := ParseFormat(argValue)
+				if err != nil {
+					return fmt.Errorf("invalid value for 'format' argument: %w", err)
+				}
+				di.Format = format
+			case "documents":
+				mode, err := ParseDocumentsMode(argValue)
+				if err != nil {
+					return fmt.Errorf("invalid value for 'documents' argument: %w", err)
+				}
+				di.Documents = mode
+			case "template":
+				isTemplate, err := strconv.ParseBool(argValue)
+				if err != nil {
+					return fmt.Errorf("invalid value for 'template' argument: must be 'true' or 'false'")
+				}
+				di.IsTemplate = isTemplate
+			default:
+				return fmt.Errorf("invalid argument %q for kind=data(): only 'format', 'documents' and 'template' are allowed", argName)
+			}
+		}
+	case "secret":
+		di.IsSecret = true
+		for argName, argValue := range kind.Args {
+			switch argName {
+			case "keyfile":
+				di.Secret.KeyFile = argValue
+			case "keyenv":
+				di.Secret.KeyEnv = argValue
+			case "format":
+				format, err := ParseFormat(argValue)
+				if err != nil {
+					return fmt.Errorf("invalid value for 'format' argument: %w", err)
+				}
+				di.Format = format
+			default:
+				return fmt.Errorf("invalid argument %q for kind=secret(): only 'keyfile', 'keyenv' and 'format' are allowed", argName)
+			}
 		}
-		di.Schema.DisableDefaults = !applyDefaults
+	default:
+		return fmt.Errorf("invalid kind %q: only 'data', 'schema' and 'secret' are supported", kind.Value)
 	}
 
 	return nil
@@ -42,6 +85,12 @@ func ParseDataArgWithTempDir(arg, tempDir string) ([]*Input, error) {
 	}
 	argParsed := parsed.Arg
 
+	if argParsed.Include != nil || argParsed.Exclude != nil {
+		return nil, fmt.Errorf("include and exclude parameters are not supported for data arguments: %s", arg)
+	}
+	if argParsed.Out != nil || argParsed.Mode != nil {
+		return nil, fmt.Errorf("out and mode parameters are not supported for data arguments: %s", arg)
+	}
 	if argParsed.JSONPath != nil {
 		if argParsed.JSONPath.Value != "" && argParsed.JSONPath.Value[0] != '$' {
 			argParsed.JSONPath.Value = "$" + argParsed.JSONPath.Value
@@ -62,9 +111,20 @@ func ParseDataArgWithTempDir(arg, tempDir string) ([]*Input, error) {
 		}
 	}
 
+	if argParsed.Format != nil {
+		di.Format, err = ParseFormat(argParsed.Format.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	formatKey := di.Format
 	if err := applyDataKindOptions(di, argParsed.Kind); err != nil {
 		return nil, err
 	}
+	if formatKey != "" && di.Format != formatKey {
+		return nil, fmt.Errorf("conflicting formats %q and %q specified for %s", formatKey, di.Format, arg)
+	}
 
 	if parsed.Auth != nil {
 		di.Auth = *parsed.Auth
@@ -70,14 +70,28 @@ func TestParseDataArg(t *testing.T) {
 			input:        "jsonpath=.Secrets,bogus=./my_secrets.yaml",
 			expectedKey:  root,
 			expectedPath: "",
-			expectError:  "invalid key 'bogus': allowed keys are auth, jsonpath, kind, path, ref, src, type",
+			expectError:  "invalid key 'bogus': allowed keys are auth, exclude, format, include, jsonpath, kind, mode, out, path, ref, src, type",
 		},
 		{
 			name:         "partial no key in entry",
 			input:        "jsonpath=.Secrets,./my_file.yaml",
 			expectedKey:  root,
 			expectedPath: "",
-			expectError:  "invalid key './my_file.yaml': allowed keys are auth, jsonpath, kind, path, ref, src, type",
+			expectError:  "invalid key './my_file.yaml': allowed keys are auth, exclude, format, include, jsonpath, kind, mode, out, path, ref, src, type",
+		},
+		{
+			name:         "exclude is only for templates",
+			input:        "src=./data,exclude=*.md",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "include and exclude parameters are not supported for data arguments",
+		},
+		{
+			name:         "out is only for templates",
+			input:        "src=./data.yaml,out=data.json",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "out and mode parameters are not supported for data arguments",
 		},
 		{
 			name:         "file named src=dumb_filename.yaml",
@@ -96,7 +110,99 @@ func TestParseDataArg(t *testing.T) {
 			input:        "src=./schema.yaml,kind=not-schema",
 			expectedKey:  root,
 			expectedPath: "",
-			expectError:  "invalid kind \"not-schema\": only 'schema' is supported",
+			expectError:  "invalid kind \"not-schema\": only 'data', 'schema' and 'secret' are supported",
+		},
+		{
+			name:         "data format override",
+			input:        "src=./inventory.txt,jsonpath=.hosts,kind=data(format=csv)",
+			expectedKey:  jsonpath.MustParse("$.hosts"),
+			expectedPath: "inventory.txt",
+		},
+		{
+			name:         "data documents mode",
+			input:        "src=./manifests.yaml,jsonpath=.manifests,kind=data(documents=list)",
+			expectedKey:  jsonpath.MustParse("$.manifests"),
+			expectedPath: "manifests.yaml",
+		},
+		{
+			name:         "data with invalid documents mode",
+			input:        "src=./manifests.yaml,kind=data(documents=first)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid value for 'documents' argument: invalid documents mode \"first\": must be 'merge' or 'list'",
+		},
+		{
+			name:         "data template",
+			input:        "src=./values.yaml.tmpl,kind=data(template=true)",
+			expectedKey:  root,
+			expectedPath: "values.yaml.tmpl",
+		},
+		{
+			name:         "data with invalid template value",
+			input:        "src=./values.yaml.tmpl,kind=data(template=yes)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid value for 'template' argument: must be 'true' or 'false'",
+		},
+		{
+			name:         "env source",
+			input:        "src=env,type=env(prefix=APP_,separator=__),jsonpath=.config",
+			expectedKey:  jsonpath.MustParse("$.config"),
+			expectedPath: "env",
+		},
+		{
+			name:         "env source requires src=env",
+			input:        "src=./values.yaml,type=env",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "env source requires src to be 'env'",
+		},
+		{
+			name:         "env source with invalid argument",
+			input:        "src=env,type=env(prefix=APP_,sep=_)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid argument \"sep\" for type=env(): only 'prefix' and 'separator' are allowed",
+		},
+		{
+			name:         "secret kind",
+			input:        "src=./secrets.sops.yaml,jsonpath=.secrets,kind=secret(keyfile=./age.txt)",
+			expectedKey:  jsonpath.MustParse("$.secrets"),
+			expectedPath: "secrets.sops.yaml",
+		},
+		{
+			name:         "secret kind with invalid argument",
+			input:        "src=./secrets.sops.yaml,kind=secret(password=x)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid argument \"password\" for kind=secret(): only 'keyfile', 'keyenv' and 'format' are allowed",
+		},
+		{
+			name:         "format key",
+			input:        "src=-,format=toml",
+			expectedKey:  root,
+			expectedPath: "-",
+		},
+		{
+			name:         "conflicting format key and kind format",
+			input:        "src=-,format=toml,kind=data(format=json)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "conflicting formats \"toml\" and \"json\"",
+		},
+		{
+			name:         "data with invalid format",
+			input:        "src=./inventory.txt,kind=data(format=docx)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid value for 'format' argument: invalid format: docx",
+		},
+		{
+			name:         "data with invalid argument",
+			input:        "src=./inventory.txt,kind=data(defaults=true)",
+			expectedKey:  root,
+			expectedPath: "",
+			expectError:  "invalid argument \"defaults\" for kind=data(): only 'format', 'documents' and 'template' are allowed",
 		},
 		{
 			name:         "schema with invalid argument",
@@ -179,6 +285,16 @@ func TestParseDataArg(t *testing.T) {
 				assert.True(t, result.IsSchema)
 				assert.True(t, result.Schema.DisableDefaults)
 			}
+			if tt.name == "data template" {
+				assert.True(t, result.IsTemplate)
+			}
+			if tt.name == "format key" {
+				assert.Equal(t, FormatTOML, result.Format)
+			}
+			if tt.name == "data format override" {
+				assert.False(t, result.IsSchema)
+				assert.Equal(t, FormatCSV, result.Format)
+			}
 		})
 	}
 }
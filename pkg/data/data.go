@@ -2,35 +2,58 @@ package data
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/rs/zerolog"
 	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
 )
 
-func applySetArgs(dst map[string]any, setArgs []string, logger *zerolog.Logger) error {
+// applySetArgs applies --set style args to the data in the order given. Paths with wildcards, filters or
+// descendant segments update every matching node, see setTargets.
+func applySetArgs(dst map[string]any, setArgs []SetArg, logger *zerolog.Logger) error {
 	if len(setArgs) == 0 {
 		return nil
 	}
 
 	mergedDataAny := any(dst)
-	for _, ss := range setArgs {
-		pathExpr, value, err := SplitSetString(ss)
+	for _, sa := range setArgs {
+		pathExpr, value, isAppend, err := sa.Parse()
 		if err != nil {
-			return fmt.Errorf("error parsing --set value '%s': %w", ss, err)
+			return fmt.Errorf("error parsing %s: %w", sa, err)
 		}
 		parsed, err := jsonpath.Parse(pathExpr)
 		if err != nil {
-			return fmt.Errorf("error parsing --set value '%s': %w", ss, err)
+			return fmt.Errorf("error parsing %s: %w", sa, err)
 		}
-		if pq := parsed.Query().Singular(); pq == nil {
-			return fmt.Errorf("error parsing --set value '%s': resulting path is not unique singular path", ss)
+		if sa.Kind == SetKindUnset {
+			root, ok := mergedDataAny.(map[string]any)
+			if !ok {
+				return fmt.Errorf("error applying %s: expected map at root, got %T", sa, mergedDataAny)
+			}
+			if err = UnsetValueInData(root, parsed); err != nil {
+				return err
+			}
+			if logger != nil {
+				logger.Debug().Msgf("unset %s", parsed)
+			}
+			continue
 		}
-		err = SetValueInData(&mergedDataAny, parsed.Query().Segments(), value, ss)
-		if err != nil {
-			return err
+
+		targets := setTargets(mergedDataAny, parsed.Query())
+		for _, segments := range targets {
+			// each target gets its own copy so that later changes to one don't show up in the others
+			if isAppend {
+				err = AppendValueInData(&mergedDataAny, segments, copyData(value), sa.Arg)
+			} else {
+				err = SetValueInData(&mergedDataAny, segments, copyData(value), sa.Arg)
+			}
+			if err != nil {
+				return err
+			}
 		}
 		if logger != nil {
-			logger.Debug().Msgf("set %s to %v", parsed, value)
+			logger.Debug().Msgf("%s %s to %v (%d matched)", sa.Kind, parsed, value, len(targets))
 		}
 	}
 
@@ -43,3 +66,41 @@ func applySetArgs(dst map[string]any, setArgs []string, logger *zerolog.Logger)
 	}
 	return nil
 }
+
+// setTargets returns the segments of each concrete path a --set path writes to. A singular path is its own
+// target and may not exist yet. For other paths ending in a single name or index, the last segment is
+// written below every node matched by the rest of the path, so "$.services[*].replicas" adds replicas to
+// every service. Otherwise, the path's existing matches are the targets.
+func setTargets(data any, query *spec.PathQuery) [][]*spec.Segment {
+	segments := query.Segments()
+	if query.Singular() != nil {
+		return [][]*spec.Segment{segments}
+	}
+
+	matchQuery := query
+	var last *spec.Segment
+	if final := segments[len(segments)-1]; !final.IsDescendant() && len(final.Selectors()) == 1 {
+		switch final.Selectors()[0].(type) {
+		case spec.Name, spec.Index:
+			matchQuery = spec.Query(true, segments[:len(segments)-1]...)
+			last = final
+		}
+	}
+
+	located := matchQuery.SelectLocated(data, data, spec.Normalized())
+	// a node matched more than once (ex: by overlapping selectors) is only written once
+	slices.SortFunc(located, func(a, b *spec.LocatedNode) int { return a.Path.Compare(b.Path) })
+	located = slices.CompactFunc(located, func(a, b *spec.LocatedNode) bool { return a.Path.Compare(b.Path) == 0 })
+	targets := make([][]*spec.Segment, 0, len(located))
+	for _, node := range located {
+		target := make([]*spec.Segment, 0, len(node.Path)+1)
+		for _, selector := range node.Path {
+			target = append(target, spec.Child(selector.(spec.Selector)))
+		}
+		if last != nil {
+			target = append(target, last)
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
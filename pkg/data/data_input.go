@@ -2,32 +2,48 @@ package data
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"path"
 	"slices"
 	"strings"
 
 	"dario.cat/mergo"
 	"github.com/adam-huganir/yutc/pkg/loader"
 	"github.com/adam-huganir/yutc/pkg/schema"
-	"github.com/goccy/go-yaml"
-	"github.com/google/jsonschema-go/jsonschema"
-	"github.com/pelletier/go-toml/v2"
 	"github.com/rs/zerolog"
 	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
 )
 
 // SchemaInfo holds configuration for schema validation.
 type SchemaInfo struct {
-	DisableDefaults bool // For schema files: skip applying defaults but still validate
+	DisableDefaults bool       // For schema files: skip applying defaults but still validate
+	Prompt          PromptFunc // Asks for missing required values before validating, if set
+}
+
+// TemplateInfo holds the template settings for data files that are rendered before decoding.
+type TemplateInfo struct {
+	Strict     bool // Error on missing keys, as with --strict
+	AllowShell bool // Allow the shell function, as with --allow-shell
 }
 
 // Input represents a data file (yaml/json/toml) or schema file for template merging.
 type Input struct {
 	*loader.FileEntry
 	JSONPath *jsonpath.Path // Optional top-level key to nest the data under
-	Schema   SchemaInfo
-	IsSchema bool // true if this is a schema file rather than a data file
+	Format   Format         // Explicit data format, detected from the file extension if empty
+	// Documents sets how a multi-document YAML file is combined, multiple documents are an error if empty
+	Documents DocumentsMode
+	Schema    SchemaInfo
+	IsSchema  bool // true if this is a schema file rather than a data file
+	Secret    SecretInfo
+	IsSecret  bool // true if this is a sops encrypted data file
+	// SecretPaths lists the locations in the merged data of values decrypted from this file, set by MergeInto
+	SecretPaths []spec.NormalizedPath
+	Template    TemplateInfo
+	IsTemplate  bool // true if this file is a go template rendered against the data merged before it
+
+	contribution map[string]any // the data this input merged, used to find where merged values came from
 }
 
 // InputOption is a functional option for configuring an Input.
@@ -49,6 +65,36 @@ func WithDefaultJSONPath() InputOption {
 	}
 }
 
+// WithFormat sets an explicit data format, overriding detection from the file extension.
+func WithFormat(format Format) InputOption {
+	return func(di *Input) {
+		di.Format = format
+	}
+}
+
+// WithDocuments sets how the documents of a multi-document YAML file are combined.
+func WithDocuments(mode DocumentsMode) InputOption {
+	return func(di *Input) {
+		di.Documents = mode
+	}
+}
+
+// AsSecret marks this Input as a sops encrypted data file.
+func AsSecret(info SecretInfo) InputOption {
+	return func(di *Input) {
+		di.IsSecret = true
+		di.Secret = info
+	}
+}
+
+// AsTemplate marks this Input as a go template that is rendered against the data merged so far.
+func AsTemplate(info TemplateInfo) InputOption {
+	return func(di *Input) {
+		di.IsTemplate = true
+		di.Template = info
+	}
+}
+
 // AsSchema marks this Input as a schema file.
 func AsSchema() InputOption {
 	return func(di *Input) {
@@ -70,26 +116,75 @@ func NewInput(name string, entryOpts []loader.FileEntryOption, dataOpts ...Input
 	return di
 }
 
-func unmarshalToMap(name string, data []byte) (map[string]any, error) {
-	fileData := make(map[string]any)
-	switch strings.ToLower(path.Ext(name)) {
-	case ".toml":
-		if err := toml.Unmarshal(data, &fileData); err != nil {
-			return nil, err
-		}
-	case ".json":
-		if err := json.Unmarshal(data, &fileData); err != nil {
-			return nil, err
-		}
-	default:
-		if err := yaml.Unmarshal(data, &fileData); err != nil {
+// DataFormat returns the format used to decode this input. In order of precedence: the explicit format,
+// the file extension (of the path, URL path, or server provided filename), the media type reported for
+// the content, and finally a guess based on the content itself.
+// A ".tmpl" extension is ignored for template inputs, so "values.yaml.tmpl" is yaml.
+func (di *Input) DataFormat() Format {
+	if di.Content == nil {
+		return di.dataFormat(nil)
+	}
+	return di.dataFormat(di.Content.Data)
+}
+
+// dataFormat is DataFormat, guessing from the given content (ex: a rendered template) as the last resort.
+func (di *Input) dataFormat(content []byte) Format {
+	if di.Format != "" {
+		return di.Format
+	}
+	name := di.Name
+	if di.Remote.URL != nil {
+		name = di.Remote.URL.Path
+	}
+	if format, ok := FormatFromExtension(di.templateName(name)); ok {
+		return format
+	}
+	if di.Content == nil {
+		return FormatYAML
+	}
+	if format, ok := FormatFromExtension(di.templateName(di.Content.Filename)); ok {
+		return format
+	}
+	if format, ok := FormatFromMimetype(di.Content.Mimetype); ok {
+		return format
+	}
+	return SniffFormat(content)
+}
+
+// templateName strips the ".tmpl" extension from name if this is a template input.
+func (di *Input) templateName(name string) string {
+	if di.IsTemplate {
+		return strings.TrimSuffix(name, ".tmpl")
+	}
+	return name
+}
+
+// decode parses the loaded content in the given format, combining multiple YAML documents if requested.
+// Secret inputs are decrypted and the paths of their decrypted values recorded relative to the file root.
+func (di *Input) decode(content []byte, format Format) (any, error) {
+	if di.IsSecret {
+		decrypted, paths, err := decryptSOPS(content, format, di.Secret)
+		if err != nil {
 			return nil, err
 		}
+		di.SecretPaths = paths
+		return decrypted, nil
+	}
+	if di.Documents == "" {
+		return decodeData(di.Name, content, format)
+	}
+	if format != FormatYAML {
+		return nil, fmt.Errorf("documents=%s is only supported for yaml data, not %s", di.Documents, format)
+	}
+	documents, err := decodeYAMLDocuments(content)
+	if err != nil {
+		return nil, err
 	}
-	return fileData, nil
+	return combineDocuments(documents, di.Documents)
 }
 
-// MergeInto loads and merges this data file into the destination map.
+// MergeInto loads and merges this data file into the destination map. Template inputs are first rendered
+// with the destination map, the data merged so far, as their data.
 func (di *Input) MergeInto(dst map[string]any, helmMode bool, specialHelmKeys []string, logger *zerolog.Logger) error {
 	if di.Content == nil || !di.Content.Read {
 		err := di.Load()
@@ -97,13 +192,31 @@ func (di *Input) MergeInto(dst map[string]any, helmMode bool, specialHelmKeys []
 			return err
 		}
 	}
-	fileData, err := unmarshalToMap(di.Name, di.Content.Data)
+	content := di.Content.Data
+	if di.IsTemplate {
+		var err error
+		content, err = renderDataTemplate(di.Name, content, dst, di.Template)
+		if err != nil {
+			return fmt.Errorf("unable to render data template %s: %w", di.Name, err)
+		}
+	}
+	format := di.dataFormat(content)
+	fileData, err := di.decode(content, format)
 	if err != nil {
 		return fmt.Errorf("unable to load data file %s: %w", di.Name, err)
 	}
 
-	dataPartial := fileData
-	if di.JSONPath != nil && di.JSONPath.String() != "$" {
+	dataPartial, isMap := fileData.(map[string]any)
+	if di.IsSecret && di.JSONPath != nil {
+		di.SecretPaths = secretPathsUnder(normalizedPrefix(di.JSONPath), di.SecretPaths)
+	}
+	if di.JSONPath == nil || di.JSONPath.String() == "$" {
+		if !isMap {
+			return fmt.Errorf(
+				"unable to merge data file %s: only objects can be merged at the root but the %s data is %s, use jsonpath= to nest it",
+				di.Name, format, describeType(fileData))
+		}
+	} else {
 		q := di.JSONPath.Query()
 		segments := di.JSONPath.Query().Segments()
 		firstKey := ""
@@ -112,9 +225,9 @@ func (di *Input) MergeInto(dst map[string]any, helmMode bool, specialHelmKeys []
 		}
 
 		logger.Debug().Msg(fmt.Sprintf("Nesting data for %s under top-level key: %s", di.Name, q.String()))
-		if helmMode && len(segments) == 1 && slices.Contains(specialHelmKeys, firstKey) {
+		if helmMode && isMap && len(segments) == 1 && slices.Contains(specialHelmKeys, firstKey) {
 			logger.Debug().Msg(fmt.Sprintf("Applying helm key transformation for %s", di.Name))
-			fileData = KeysToPascalCase(fileData)
+			fileData = KeysToPascalCase(dataPartial)
 		}
 		partial := make(map[string]any)
 		partialAny := any(partial)
@@ -129,6 +242,7 @@ func (di *Input) MergeInto(dst map[string]any, helmMode bool, specialHelmKeys []
 		}
 	}
 
+	di.contribution, _ = copyData(dataPartial).(map[string]any)
 	err = mergo.Merge(&dst, dataPartial, mergo.WithOverride)
 	if err != nil {
 		return err
@@ -136,53 +250,106 @@ func (di *Input) MergeInto(dst map[string]any, helmMode bool, specialHelmKeys []
 	return nil
 }
 
-// ApplySchemaTo validates and optionally applies defaults from this schema to the data.
+// ApplySchemaTo applies defaults from this schema to the data, unless disabled, prompts for missing required
+// values, if enabled, and validates it. Schemas it references with $ref are loaded relative to it. All
+// violations are returned as a *SchemaError.
 func (di *Input) ApplySchemaTo(data map[string]any) error {
+	compiled, err := di.CompileSchema()
+	if err != nil {
+		return err
+	}
+
+	target, prefix, found := di.schemaTarget(data)
+	if !found {
+		di.Logger().Debug().Msgf("Nothing at %s to validate with schema %s", di.JSONPath, di.Name)
+		return nil
+	}
+
+	if !di.Schema.DisableDefaults {
+		if err = compiled.ApplyDefaults(target); err != nil {
+			return fmt.Errorf("unable to apply defaults from schema %s: %w", di.Name, err)
+		}
+	}
+	if di.Schema.Prompt != nil {
+		if err = di.promptMissing(compiled, target, prefix); err != nil {
+			return err
+		}
+	}
+	violations, err := compiled.Validate(target)
+	if err != nil {
+		return fmt.Errorf("unable to validate schema %s: %w", di.Name, err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	schemaErr := &SchemaError{Schema: di.Name}
+	for _, v := range violations {
+		schemaErr.Violations = append(schemaErr.Violations, SchemaViolation{
+			Location: append(slices.Clone(prefix), pointerLocation(target, v.Pointer)...),
+			Message:  v.Message,
+		})
+	}
+	return schemaErr
+}
+
+// CompileSchema loads this schema input and compiles it with the schemas it references with $ref, which are
+// loaded relative to it.
+func (di *Input) CompileSchema() (*schema.Compiled, error) {
 	if di.Content == nil || !di.Content.Read {
 		err := di.Load()
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	fileData, err := unmarshalToMap(di.Name, di.Content.Data)
+	doc, err := decodeData(di.Name, di.Content.Data, di.DataFormat())
 	if err != nil {
-		return fmt.Errorf("unable to load data file %s: %w", di.Name, err)
+		return nil, fmt.Errorf("unable to load data file %s: %w", di.Name, err)
 	}
-	schemaBytes, err := json.Marshal(fileData)
+	location, err := di.schemaURL()
 	if err != nil {
-		return fmt.Errorf("unable to marshal schema %s: %w", di.Name, err)
+		return nil, fmt.Errorf("unable to load schema %s: %w", di.Name, err)
 	}
-	s, err := schema.LoadSchema(schemaBytes)
+	compiled, err := schema.Compile(location, doc, di.loadSchemaRef)
 	if err != nil {
-		return fmt.Errorf("unable to load schema %s: %w", di.Name, err)
+		return nil, fmt.Errorf("unable to load schema %s: %w", di.Name, err)
 	}
-	if di.JSONPath != nil && di.JSONPath.String() != "$" {
-		s = schema.NestSchema(s, di.JSONPath.String())
+	return compiled, nil
+}
+
+// SchemaRoot returns the path of object keys, and schema.ItemSegment for array elements, to the value in data
+// this schema input applies to, the first match of its jsonpath or the root. It returns false if nothing matches.
+func (di *Input) SchemaRoot(data map[string]any) ([]string, bool) {
+	_, location, found := di.schemaTarget(data)
+	if !found {
+		return nil, false
 	}
-	var resolvedSchema *jsonschema.Resolved
-	if di.Schema.DisableDefaults {
-		resolvedSchema, err = s.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: false})
-		if err != nil {
-			return fmt.Errorf("unable to resolve schema %s: %w", di.Name, err)
-		}
-	} else {
-		resolvedSchema, err = schema.ApplyDefaults(data, s)
-		if err != nil {
-			return fmt.Errorf("unable to resolve schema %s: %w", di.Name, err)
+	path := make([]string, len(location))
+	for i, selector := range location {
+		if name, ok := selector.(spec.Name); ok {
+			path[i] = string(name)
+		} else {
+			path[i] = schema.ItemSegment
 		}
 	}
+	return path, true
+}
 
-	err = resolvedSchema.Validate(data)
-	if err != nil {
-		return fmt.Errorf("unable to validate schema %s: %w", di.Name, err)
+// schemaTarget returns the value in data this schema input applies to and its location.
+func (di *Input) schemaTarget(data map[string]any) (any, spec.NormalizedPath, bool) {
+	if di.JSONPath == nil || di.JSONPath.String() == "$" {
+		return data, spec.Normalized(), true
 	}
-	return nil
+	located := di.JSONPath.SelectLocated(data)
+	if len(located) == 0 {
+		return nil, nil, false
+	}
+	return located[0].Node, located[0].Path, true
 }
 
 // MergeDataFiles merges data from a list of Input and returns a map of the merged data.
 // The data is merged in the order of the inputs, with later data overriding earlier ones.
 // Schema inputs are applied after all data and --set args are merged.
-func MergeDataFiles(dataFiles []*Input, setArgs []string, helmMode bool, logger *zerolog.Logger) (data map[string]any, err error) {
+func MergeDataFiles(dataFiles []*Input, setArgs []SetArg, helmMode bool, logger *zerolog.Logger) (data map[string]any, err error) {
 	data = make(map[string]any)
 	// since some of helms data structures are go structs, when the chart file is accessed through templates
 	// it uses the struct casing rather than the yaml casing. this adjusts for that. for right now we only do this
@@ -202,7 +369,7 @@ func MergeDataFiles(dataFiles []*Input, setArgs []string, helmMode bool, logger
 	}
 
 	processDataInput := func(dataArg *Input) error {
-		isDir, err := loader.IsDir(dataArg.Name)
+		isDir, err := dataArg.IsDir()
 		if err != nil {
 			return err
 		}
@@ -220,6 +387,15 @@ func MergeDataFiles(dataFiles []*Input, setArgs []string, helmMode bool, logger
 
 		if dataArg.IsSchema {
 			err = dataArg.ApplySchemaTo(data)
+			var schemaErr *SchemaError
+			if errors.As(err, &schemaErr) {
+				// every input contributes to the root, so only values below it have a meaningful origin
+				for i, v := range schemaErr.Violations {
+					if len(v.Location) > 0 {
+						schemaErr.Violations[i].Origin = Origin(dataFiles, setArgs, v.Location)
+					}
+				}
+			}
 			if err != nil {
 				return err
 			}
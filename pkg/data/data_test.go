@@ -1,6 +1,9 @@
 package data
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -11,6 +14,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
 )
 
 func TestMergeData(t *testing.T) {
@@ -217,6 +221,156 @@ func TestMergeDataWithKeys(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "nest csv rows by path",
+			setupFiles: map[string]string{
+				"hosts.csv": util.MustDedent(`
+									host,role
+									web-1,web`),
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "hosts.csv"}, JSONPath: jsonpath.MustParse("$.hosts")},
+			},
+			expectedData: map[string]any{
+				"hosts": []any{
+					map[string]any{"host": "web-1", "role": "web"},
+				},
+			},
+		},
+		{
+			name: "csv rows at root",
+			setupFiles: map[string]string{
+				"hosts.csv": util.MustDedent(`
+									host,role
+									web-1,web`),
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "hosts.csv"}, JSONPath: jsonpath.MustParse("$")},
+			},
+			expectError: true,
+		},
+		{
+			name: "nest json array by path",
+			setupFiles: map[string]string{
+				"items.json": `[{"name": "a"}, {"name": "b"}]`,
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "items.json"}, JSONPath: jsonpath.MustParse("$.items")},
+			},
+			expectedData: map[string]any{
+				"items": []any{
+					map[string]any{"name": "a"},
+					map[string]any{"name": "b"},
+				},
+			},
+		},
+		{
+			name: "nest yaml scalar by path",
+			setupFiles: map[string]string{
+				"version.yaml": "1.2.3",
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "version.yaml"}, JSONPath: jsonpath.MustParse("$.app.version")},
+			},
+			expectedData: map[string]any{
+				"app": map[string]any{"version": "1.2.3"},
+			},
+		},
+		{
+			name: "json array at root",
+			setupFiles: map[string]string{
+				"items.json": `[{"name": "a"}]`,
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "items.json"}, JSONPath: jsonpath.MustParse("$")},
+			},
+			expectError: true,
+		},
+		{
+			name: "empty yaml at root",
+			setupFiles: map[string]string{
+				"empty.yaml": "",
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "empty.yaml"}, JSONPath: jsonpath.MustParse("$")},
+			},
+			expectedData: map[string]any{},
+		},
+		{
+			name: "explicit format overrides extension",
+			setupFiles: map[string]string{
+				"settings.txt": util.MustDedent(`
+									[server]
+									port = 80`),
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "settings.txt"}, JSONPath: jsonpath.MustParse("$"), Format: FormatINI},
+			},
+			expectedData: map[string]any{
+				"server": map[string]any{"port": "80"},
+			},
+		},
+		{
+			name: "multi-document yaml merged",
+			setupFiles: map[string]string{
+				"manifests.yaml": util.MustDedent(`
+									app:
+									  name: web
+									  replicas: 1
+									---
+									# comment only document
+									---
+									app:
+									  replicas: 3
+									extra: true`),
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "manifests.yaml"}, JSONPath: jsonpath.MustParse("$"), Documents: DocumentsMerge},
+			},
+			expectedData: map[string]any{
+				"app":   map[string]any{"name": "web", "replicas": uint64(3)},
+				"extra": true,
+			},
+		},
+		{
+			name: "multi-document yaml as list",
+			setupFiles: map[string]string{
+				"manifests.yaml": util.MustDedent(`
+									kind: Service
+									---
+									---
+									kind: Deployment`),
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "manifests.yaml"}, JSONPath: jsonpath.MustParse("$.manifests"), Documents: DocumentsList},
+			},
+			expectedData: map[string]any{
+				"manifests": []any{
+					map[string]any{"kind": "Service"},
+					map[string]any{"kind": "Deployment"},
+				},
+			},
+		},
+		{
+			name: "multi-document yaml without documents option",
+			setupFiles: map[string]string{
+				"manifests.yaml": "a: 1\n---\nb: 2\n",
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "manifests.yaml"}, JSONPath: jsonpath.MustParse("$")},
+			},
+			expectedData: map[string]any{"a": uint64(1)},
+		},
+		{
+			name: "multi-document yaml list at root",
+			setupFiles: map[string]string{
+				"manifests.yaml": "a: 1\n---\nb: 2\n",
+			},
+			dataFileArgs: []*Input{
+				{FileEntry: &loader.FileEntry{Name: "manifests.yaml"}, JSONPath: jsonpath.MustParse("$"), Documents: DocumentsList},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -242,7 +396,7 @@ func TestMergeDataWithKeys(t *testing.T) {
 			var currentDataFileArgs []*Input
 			for _, dfa := range tt.dataFileArgs {
 				actualPath := filepath.Join(tmpDir, dfa.Name)
-				di := NewInput(actualPath, nil, WithJSONPath(dfa.JSONPath))
+				di := NewInput(actualPath, nil, WithJSONPath(dfa.JSONPath), WithFormat(dfa.Format), WithDocuments(dfa.Documents))
 				currentDataFileArgs = append(currentDataFileArgs, di)
 			}
 
@@ -288,7 +442,7 @@ func TestMergeDataFiles_SetDataMergeOrder(t *testing.T) {
 	fs := NewInput(schemaFile, nil, AsSchema())
 	fileArgs = append(fileArgs, f1, f2, fs)
 
-	setArgs := []string{"$.a=5"}
+	setArgs := []SetArg{{Kind: SetKindValue, Arg: "$.a=5"}}
 
 	logger := zerolog.Nop()
 	merged, err := MergeDataFiles(fileArgs, setArgs, false, &logger)
@@ -340,3 +494,231 @@ func TestLoadTemplates(t *testing.T) {
 	err := os.WriteFile(tmplFile, []byte("{{ .key }}"), 0o644)
 	assert.NoError(t, err)
 }
+
+func TestMergeDataFiles_FormatDetection(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/items":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_, _ = w.Write([]byte(`{"items": {"count": 2}}`))
+		case "/config":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("[server]\nport = 8080\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir := t.TempDir()
+	extensionless := filepath.Join(tmpDir, "values")
+	assert.NoError(t, os.WriteFile(extensionless, []byte("name = \"app\"\n"), 0o644))
+
+	inputs := []*Input{
+		NewInput(ts.URL+"/api/items", nil),
+		NewInput(ts.URL+"/config", nil),
+		NewInput(extensionless, nil),
+	}
+	for _, di := range inputs {
+		assert.NoError(t, di.Load())
+	}
+	assert.Equal(t, FormatJSON, inputs[0].DataFormat())
+	assert.Equal(t, FormatTOML, inputs[1].DataFormat())
+	assert.Equal(t, FormatTOML, inputs[2].DataFormat())
+
+	logger := zerolog.Nop()
+	merged, err := MergeDataFiles(inputs, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"items":  map[string]any{"count": float64(2)},
+		"server": map[string]any{"port": int64(8080)},
+		"name":   "app",
+	}, merged)
+
+	explicit := NewInput(extensionless, nil, WithFormat(FormatYAML))
+	assert.Equal(t, FormatYAML, explicit.DataFormat())
+}
+
+func TestMergeDataFiles_Template(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.yaml")
+	assert.NoError(t, os.WriteFile(base, []byte("name: api\nreplicas: 2\n"), 0o644))
+	derived := filepath.Join(tmpDir, "derived.yaml.tmpl")
+	assert.NoError(t, os.WriteFile(derived, []byte(util.MustDedent(`
+		fullname: {{ .name | upper }}-svc
+		replicas: {{ mul .replicas 2 }}
+	`)), 0o644))
+	missing := filepath.Join(tmpDir, "missing.yaml.tmpl")
+	assert.NoError(t, os.WriteFile(missing, []byte("value: {{ .nope.deeper }}\n"), 0o644))
+
+	logger := zerolog.Nop()
+	merged, err := MergeDataFiles([]*Input{
+		NewInput(base, nil),
+		NewInput(derived, nil, AsTemplate(TemplateInfo{})),
+	}, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "API-svc", merged["fullname"])
+	assert.EqualValues(t, 4, merged["replicas"])
+	assert.Equal(t, "api", merged["name"])
+
+	_, err = MergeDataFiles([]*Input{
+		NewInput(base, nil),
+		NewInput(missing, nil, AsTemplate(TemplateInfo{Strict: true})),
+	}, nil, false, &logger)
+	assert.ErrorContains(t, err, "unable to render data template")
+
+	// the .tmpl extension is ignored when detecting the format of a template
+	assert.Equal(t, FormatYAML, NewInput(derived, nil, AsTemplate(TemplateInfo{})).DataFormat())
+}
+
+func TestMergeDataFiles_SchemaRefsAndErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schemas/port.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"type": "integer", "minimum": 1, "default": 8080}`))
+	}))
+	defer ts.Close()
+
+	tmpDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "defs"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(tmpDir, "defs", "db.yaml"), []byte(util.MustDedent(`
+		type: object
+		properties:
+		  host:
+		    type: string
+		    default: localhost
+		  port:
+		    $ref: `+ts.URL+`/schemas/port.json
+	`)), 0o644))
+	schemaFile := filepath.Join(tmpDir, "schema.yaml")
+	assert.NoError(t, os.WriteFile(schemaFile, []byte(util.MustDedent(`
+		$schema: http://json-schema.org/draft-07/schema#
+		type: object
+		required: [name]
+		properties:
+		  name:
+		    type: string
+		  db:
+		    $ref: defs/db.yaml
+	`)), 0o644))
+	base := filepath.Join(tmpDir, "base.yaml")
+	assert.NoError(t, os.WriteFile(base, []byte("name: app\ndb: {}\n"), 0o644))
+	override := filepath.Join(tmpDir, "override.yaml")
+	assert.NoError(t, os.WriteFile(override, []byte("name: 5\n"), 0o644))
+
+	logger := zerolog.Nop()
+	merged, err := MergeDataFiles([]*Input{NewInput(base, nil), NewInput(schemaFile, nil, AsSchema())}, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "port": float64(8080)}, merged["db"])
+
+	_, err = MergeDataFiles([]*Input{
+		NewInput(base, nil),
+		NewInput(override, nil),
+		NewInput(schemaFile, nil, AsSchema()),
+	}, NewSetArgs(SetKindValue, []string{".db.port=0"}), false, &logger)
+	var schemaErr *SchemaError
+	if assert.ErrorAs(t, err, &schemaErr) {
+		assert.Equal(t, []SchemaViolation{
+			{Location: spec.Normalized(spec.Name("db"), spec.Name("port")), Message: "minimum: got 0, want 1", Origin: "--set .db.port=0"},
+			{Location: spec.Normalized(spec.Name("name")), Message: "got number, want string", Origin: loader.NormalizeFilepath(override)},
+		}, schemaErr.Violations)
+	}
+	assert.ErrorContains(t, err, "2 error(s)\n  /db/port: minimum: got 0, want 1 (from --set .db.port=0)")
+}
+
+func TestMergeDataFiles_SchemaRefAuth(t *testing.T) {
+	var otherAuth string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"type": "integer", "default": 8080}`))
+	}))
+	defer other.Close()
+
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	assert.NoError(t, os.WriteFile(secret, []byte(`{"type": "string"}`), 0o644))
+	secretURL := (&url.URL{Scheme: "file", Path: filepath.ToSlash(secret)}).String()
+
+	sameAuth := make(map[string]string)
+	own := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sameAuth[r.URL.Path] = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/schema.json":
+			_, _ = w.Write([]byte(`{"properties": {"name": {"$ref": "name.json"}, "port": {"$ref": "` + other.URL + `/port.json"}}}`))
+		case "/name.json":
+			_, _ = w.Write([]byte(`{"type": "string", "default": "app"}`))
+		case "/local.json":
+			_, _ = w.Write([]byte(`{"properties": {"name": {"$ref": "` + secretURL + `"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer own.Close()
+
+	logger := zerolog.Nop()
+	auth := loader.WithAuth(loader.AuthInfo{BearerToken: "token"})
+	merged, err := MergeDataFiles([]*Input{NewInput(own.URL+"/schema.json", []loader.FileEntryOption{auth}, AsSchema())}, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "app", "port": float64(8080)}, merged)
+	assert.Equal(t, "Bearer token", sameAuth["/name.json"])
+	assert.Empty(t, otherAuth)
+
+	_, err = MergeDataFiles([]*Input{NewInput(own.URL+"/local.json", []loader.FileEntryOption{auth}, AsSchema())}, nil, false, &logger)
+	assert.ErrorContains(t, err, "cannot reference local files")
+}
+
+func TestMergeDataFiles_Prompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	schemaFile := filepath.Join(tmpDir, "schema.yaml")
+	assert.NoError(t, os.WriteFile(schemaFile, []byte(util.MustDedent(`
+		type: object
+		required: [name, project]
+		properties:
+		  name:
+		    type: string
+		    description: Name of the project
+		  project:
+		    type: object
+		    required: [python, private]
+		    properties:
+		      python:
+		        type: string
+		      private:
+		        type: boolean
+	`)), 0o644))
+	base := filepath.Join(tmpDir, "base.yaml")
+	assert.NoError(t, os.WriteFile(base, []byte("project:\n  private: true\n"), 0o644))
+
+	logger := zerolog.Nop()
+	var asked []MissingValue
+	schemaInput := NewInput(schemaFile, nil, AsSchema())
+	schemaInput.Schema.Prompt = func(_ string, missing []MissingValue) ([]any, error) {
+		asked = missing
+		return []any{"app", "3.12"}, nil
+	}
+	merged, err := MergeDataFiles([]*Input{NewInput(base, nil), schemaInput}, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"name": "app", "project": map[string]any{"private": true, "python": "3.12"}}, merged)
+	if assert.Len(t, asked, 2) {
+		assert.Equal(t, "/name", asked[0].Pointer())
+		assert.Equal(t, "Name of the project", asked[0].Description)
+		assert.Equal(t, "/project/python", asked[1].Pointer())
+	}
+
+	noPrompt := NewInput(schemaFile, nil, AsSchema())
+	noPrompt.Schema.Prompt = NoPrompt
+	_, err = MergeDataFiles([]*Input{noPrompt}, nil, false, &logger)
+	var missingErr *MissingValuesError
+	if assert.ErrorAs(t, err, &missingErr) {
+		assert.Len(t, missingErr.Missing, 3)
+	}
+	assert.ErrorContains(t, err, "stdin is not a terminal\n  /name: Name of the project\n  /project/python\n  /project/private")
+}
+
+func TestPointerLocation(t *testing.T) {
+	data := map[string]any{"items": []any{map[string]any{"a/b": 1}}, "m": map[string]any{"0": 1}}
+	assert.Equal(t, spec.Normalized(spec.Name("items"), spec.Index(0), spec.Name("a/b")), pointerLocation(data, "/items/0/a~1b"))
+	assert.Equal(t, spec.Normalized(spec.Name("m"), spec.Name("0")), pointerLocation(data, "/m/0"))
+	assert.Equal(t, spec.Normalized(), pointerLocation(data, ""))
+}
@@ -0,0 +1,314 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"dario.cat/mergo"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/lexer"
+	"github.com/goccy/go-yaml/token"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"gopkg.in/ini.v1"
+)
+
+// decodeData parses raw file data in the given format. YAML and JSON documents may have any type at
+// the root, CSV decodes to a list of row maps keyed by the header row, and every other format decodes
+// to a map. An empty document decodes to an empty map. Only the first document of a YAML stream is
+// decoded, use decodeYAMLDocuments for the others.
+func decodeData(name string, data []byte, format Format) (any, error) {
+	switch format {
+	case FormatYAML, "":
+		var fileData any
+		if err := yaml.Unmarshal(data, &fileData); err != nil {
+			return nil, err
+		}
+		return emptyToMap(fileData), nil
+	case FormatJSON:
+		var fileData any
+		if err := json.Unmarshal(data, &fileData); err != nil {
+			return nil, err
+		}
+		return emptyToMap(fileData), nil
+	case FormatTOML:
+		fileData := make(map[string]any)
+		if err := toml.Unmarshal(data, &fileData); err != nil {
+			return nil, err
+		}
+		return fileData, nil
+	case FormatHCL:
+		return decodeHCL(name, data)
+	case FormatINI:
+		return decodeINI(data)
+	case FormatEnv:
+		return decodeEnv(data)
+	case FormatCSV:
+		return decodeCSV(data)
+	case FormatXML:
+		return decodeXML(data)
+	default:
+		return nil, fmt.Errorf("unsupported data format: %s", format)
+	}
+}
+
+// decodeYAMLDocuments decodes every document in a YAML stream, skipping empty and null documents.
+// Directives such as %YAML stay with the document they precede.
+func decodeYAMLDocuments(data []byte) ([]any, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(fillEmptyDocuments(data)))
+	var documents []any
+	for i := 1; ; i++ {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+}
+
+// fillEmptyDocuments gives every empty document of a YAML stream an explicit null ("--- ~"), as the
+// yaml decoder stops at the first empty document and drops the documents after it.
+func fillEmptyDocuments(data []byte) []byte {
+	tokens := lexer.Tokenize(string(data))
+	lines := strings.SplitAfter(string(data), "\n")
+	filled := false
+	for i, tk := range tokens {
+		if tk.Type != token.DocumentHeaderType {
+			continue
+		}
+		next := i + 1
+		for next < len(tokens) && tokens[next].Type == token.CommentType {
+			next++
+		}
+		if next < len(tokens) && tokens[next].Type != token.DocumentHeaderType && tokens[next].Type != token.DocumentEndType {
+			continue
+		}
+		line := lines[tk.Position.Line-1]
+		if !strings.HasPrefix(line, "---") {
+			continue
+		}
+		lines[tk.Position.Line-1] = line[:3] + " ~" + line[3:]
+		filled = true
+	}
+	if !filled {
+		return data
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// combineDocuments combines the documents of a multi-document file according to mode. DocumentsMerge
+// deep-merges every document in order, later documents taking precedence, and DocumentsList returns
+// the documents as a list.
+func combineDocuments(documents []any, mode DocumentsMode) (any, error) {
+	switch mode {
+	case DocumentsList:
+		return documents, nil
+	case DocumentsMerge:
+		merged := make(map[string]any)
+		for i, document := range documents {
+			documentMap, ok := document.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("unable to merge document %d: only objects can be merged but it is %s", i+1, describeType(document))
+			}
+			if err := mergo.Merge(&merged, documentMap, mergo.WithOverride); err != nil {
+				return nil, fmt.Errorf("unable to merge document %d: %w", i+1, err)
+			}
+		}
+		return merged, nil
+	default:
+		return nil, fmt.Errorf("unsupported documents mode: %s", mode)
+	}
+}
+
+// emptyToMap substitutes an empty map for a null document so that empty files merge as no-ops.
+func emptyToMap(v any) any {
+	if v == nil {
+		return make(map[string]any)
+	}
+	return v
+}
+
+// describeType returns a user facing name for the type of decoded data.
+func describeType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "a list"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	case float64, float32, int, int64, uint64, int32, uint32:
+		return "a number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// decodeHCL decodes the top level attributes of an HCL file (e.g. a terraform .tfvars file).
+// Blocks are not supported as they have no unambiguous representation without a schema.
+func decodeHCL(name string, data []byte) (map[string]any, error) {
+	file, diags := hclsyntax.ParseConfig(data, name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	attrs, diags := file.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	fileData := make(map[string]any, len(attrs))
+	for key, attr := range attrs {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		b, err := ctyjson.SimpleJSONValue{Value: value}.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert hcl attribute %s: %w", key, err)
+		}
+		var v any
+		if err = json.Unmarshal(b, &v); err != nil {
+			return nil, fmt.Errorf("unable to convert hcl attribute %s: %w", key, err)
+		}
+		fileData[key] = v
+	}
+	return fileData, nil
+}
+
+// decodeINI decodes an INI file, keys outside any section are placed at the root and each
+// section becomes a nested map. All values are strings.
+func decodeINI(data []byte) (map[string]any, error) {
+	cfg, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	fileData := make(map[string]any)
+	for _, section := range cfg.Sections() {
+		target := fileData
+		if section.Name() != ini.DefaultSection {
+			target = make(map[string]any)
+			fileData[section.Name()] = target
+		}
+		for _, key := range section.Keys() {
+			target[key.Name()] = key.Value()
+		}
+	}
+	return fileData, nil
+}
+
+// decodeEnv decodes a dotenv file into a flat map of strings.
+func decodeEnv(data []byte) (map[string]any, error) {
+	env, err := godotenv.UnmarshalBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	fileData := make(map[string]any, len(env))
+	for k, v := range env {
+		fileData[k] = v
+	}
+	return fileData, nil
+}
+
+// decodeCSV decodes a CSV file with a header row into a list of maps, one per row.
+func decodeCSV(data []byte) ([]any, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]any, 0, max(len(records)-1, 0))
+	if len(records) == 0 {
+		return rows, nil
+	}
+	header := records[0]
+	for _, record := range records[1:] {
+		row := make(map[string]any, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// decodeXML decodes an XML document into nested maps keyed by element name, with the root element
+// as the only top-level key. Attributes are stored under "@name" and text content of elements that also
+// have attributes or children under "#text". Repeated child elements become lists.
+func decodeXML(data []byte) (map[string]any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			root, err := decodeXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{start.Name.Local: root}, nil
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (any, error) {
+	element := make(map[string]any)
+	for _, attr := range start.Attr {
+		element["@"+attr.Name.Local] = attr.Value
+	}
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+			key := t.Name.Local
+			switch existing := element[key].(type) {
+			case nil:
+				element[key] = child
+			case []any:
+				element[key] = append(existing, child)
+			default:
+				element[key] = []any{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				element["#text"] = content
+			}
+			return element, nil
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      Format
+		content     string
+		expected    any
+		expectError bool
+	}{
+		{
+			name:     "yaml list root",
+			format:   FormatYAML,
+			content:  "- a\n- b",
+			expected: []any{"a", "b"},
+		},
+		{
+			name:     "json scalar root",
+			format:   FormatJSON,
+			content:  `"hello"`,
+			expected: "hello",
+		},
+		{
+			name:     "yaml empty document",
+			format:   FormatYAML,
+			content:  "# nothing here",
+			expected: map[string]any{},
+		},
+		{
+			name:     "yaml leading document marker",
+			format:   FormatYAML,
+			content:  "---\na: b\n",
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			name:     "yaml directive",
+			format:   FormatYAML,
+			content:  "%YAML 1.2\n---\na: 1\n",
+			expected: map[string]any{"a": uint64(1)},
+		},
+		{
+			name:     "yaml multiple documents",
+			format:   FormatYAML,
+			content:  "a: b\n---\nc: d\n",
+			expected: map[string]any{"a": "b"},
+		},
+		{
+			name:   "hcl tfvars",
+			format: FormatHCL,
+			content: util.MustDedent(`
+				region        = "us-east-1"
+				instance_count = 3
+				tags = {
+				  team = "platform"
+				}
+				zones = ["a", "b"]`),
+			expected: map[string]any{
+				"region":         "us-east-1",
+				"instance_count": float64(3),
+				"tags":           map[string]any{"team": "platform"},
+				"zones":          []any{"a", "b"},
+			},
+		},
+		{
+			name:   "hcl blocks are rejected",
+			format: FormatHCL,
+			content: util.MustDedent(`
+				resource "a" "b" {
+				  c = 1
+				}`),
+			expectError: true,
+		},
+		{
+			name:   "ini",
+			format: FormatINI,
+			content: util.MustDedent(`
+				name = app
+				[database]
+				host = localhost
+				port = 5432`),
+			expected: map[string]any{
+				"name":     "app",
+				"database": map[string]any{"host": "localhost", "port": "5432"},
+			},
+		},
+		{
+			name:   "dotenv",
+			format: FormatEnv,
+			content: util.MustDedent(`
+				# comment
+				export DB_HOST=localhost
+				GREETING="hello world"`),
+			expected: map[string]any{
+				"DB_HOST":  "localhost",
+				"GREETING": "hello world",
+			},
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			content: util.MustDedent(`
+				host,role
+				web-1,web
+				"db-1",db`),
+			expected: []any{
+				map[string]any{"host": "web-1", "role": "web"},
+				map[string]any{"host": "db-1", "role": "db"},
+			},
+		},
+		{
+			name:     "csv empty",
+			format:   FormatCSV,
+			content:  "",
+			expected: []any{},
+		},
+		{
+			name:   "csv ragged rows",
+			format: FormatCSV,
+			content: util.MustDedent(`
+				host,role
+				web-1`),
+			expectError: true,
+		},
+		{
+			name:   "xml",
+			format: FormatXML,
+			content: util.MustDedent(`
+				<?xml version="1.0"?>
+				<config env="prod">
+				  <name>app</name>
+				  <server>one</server>
+				  <server>two</server>
+				  <port protocol="tcp">8080</port>
+				</config>`),
+			expected: map[string]any{
+				"config": map[string]any{
+					"@env":   "prod",
+					"name":   "app",
+					"server": []any{"one", "two"},
+					"port":   map[string]any{"@protocol": "tcp", "#text": "8080"},
+				},
+			},
+		},
+		{
+			name:        "xml empty",
+			format:      FormatXML,
+			content:     "",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decodeData("test", []byte(tt.content), tt.format)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, decoded)
+		})
+	}
+}
+
+func TestCombineDocuments(t *testing.T) {
+	content := util.MustDedent(`
+		a:
+		  b: 1
+		  c: [1, 2]
+		---
+		~
+		---
+		a:
+		  b: 2
+		  c: [3]
+		d: e`)
+	documents, err := decodeYAMLDocuments([]byte(content))
+	assert.NoError(t, err)
+	assert.Len(t, documents, 2)
+
+	merged, err := combineDocuments(documents, DocumentsMerge)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": map[string]any{"b": uint64(2), "c": []any{uint64(3)}},
+		"d": "e",
+	}, merged)
+
+	list, err := combineDocuments(documents, DocumentsList)
+	assert.NoError(t, err)
+	assert.Equal(t, documents, list)
+
+	documents, err = decodeYAMLDocuments([]byte("%YAML 1.2\n---\na: 1\n...\n%YAML 1.2\n---\nb: 2\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"a": uint64(1)}, map[string]any{"b": uint64(2)}}, documents)
+
+	documents, err = decodeYAMLDocuments([]byte("a: 1\n---\n---\n# empty\n...\n---\nb: 2\n---\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"a": uint64(1)}, map[string]any{"b": uint64(2)}}, documents)
+
+	_, err = combineDocuments([]any{map[string]any{}, []any{"x"}}, DocumentsMerge)
+	assert.ErrorContains(t, err, "unable to merge document 2: only objects can be merged but it is a list")
+}
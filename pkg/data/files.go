@@ -24,6 +24,11 @@ func CountDataRecursables(paths []*Input) (int, error) {
 
 // ResolveDataPaths parses data path strings, loads their content, and expands directories.
 func ResolveDataPaths(paths []string, tempDir string, logger *zerolog.Logger) ([]*Input, error) {
+	return ResolveDataPathsRelativeTo(paths, "", tempDir, logger)
+}
+
+// ResolveDataPathsRelativeTo is ResolveDataPaths with relative file paths resolved against dir.
+func ResolveDataPathsRelativeTo(paths []string, dir, tempDir string, logger *zerolog.Logger) ([]*Input, error) {
 	var outFiles []*Input
 	for _, p := range paths {
 		dis, err := ParseDataArgWithTempDir(p, tempDir)
@@ -31,6 +36,7 @@ func ResolveDataPaths(paths []string, tempDir string, logger *zerolog.Logger) ([
 			return nil, err
 		}
 		for _, di := range dis {
+			di.ResolveRelativeTo(dir)
 			di.SetLogger(logger)
 			err = di.Load()
 			if err != nil && !errors.Is(err, loader.ErrIsContainer) {
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Format identifies a serialization format for data files.
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	FormatHCL  Format = "hcl"
+	FormatINI  Format = "ini"
+	FormatEnv  Format = "env"
+	FormatCSV  Format = "csv"
+	FormatXML  Format = "xml"
+)
+
+func (f Format) String() string {
+	return string(f)
+}
+
+// DocumentsMode controls how the documents of a multi-document YAML file are combined.
+type DocumentsMode string
+
+const (
+	DocumentsMerge DocumentsMode = "merge"
+	DocumentsList  DocumentsMode = "list"
+)
+
+// ParseDocumentsMode converts a documents mode name into a DocumentsMode.
+func ParseDocumentsMode(value string) (DocumentsMode, error) {
+	switch DocumentsMode(strings.ToLower(strings.TrimSpace(value))) {
+	case DocumentsMerge:
+		return DocumentsMerge, nil
+	case DocumentsList:
+		return DocumentsList, nil
+	default:
+		return "", fmt.Errorf("invalid documents mode %q: must be 'merge' or 'list'", value)
+	}
+}
+
+// ParseFormat converts a format name (case-insensitive, "yml" is accepted as an alias) into a Format.
+func ParseFormat(value string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	case "toml":
+		return FormatTOML, nil
+	case "hcl", "tfvars":
+		return FormatHCL, nil
+	case "ini":
+		return FormatINI, nil
+	case "env", "dotenv":
+		return FormatEnv, nil
+	case "csv":
+		return FormatCSV, nil
+	case "xml":
+		return FormatXML, nil
+	case "":
+		return "", fmt.Errorf("format is empty")
+	default:
+		return "", fmt.Errorf("invalid format: %s", value)
+	}
+}
+
+// ParseOutputFormat is ParseFormat for the formats data can be written in: YAML, JSON and TOML.
+func ParseOutputFormat(value string) (Format, error) {
+	format, err := ParseFormat(value)
+	if err != nil {
+		return "", err
+	}
+	switch format {
+	case FormatYAML, FormatJSON, FormatTOML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s, must be one of: yaml, json, toml", format)
+	}
+}
+
+// FormatFromExtension returns the data format implied by a file name's extension, and false if the
+// extension is not recognized.
+func FormatFromExtension(name string) (Format, bool) {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".json":
+		return FormatJSON, true
+	case ".toml":
+		return FormatTOML, true
+	case ".hcl", ".tfvars":
+		return FormatHCL, true
+	case ".ini":
+		return FormatINI, true
+	case ".env":
+		return FormatEnv, true
+	case ".csv":
+		return FormatCSV, true
+	case ".xml":
+		return FormatXML, true
+	default:
+		return "", false
+	}
+}
+
+// FormatFromMimetype returns the data format for a media type (without parameters), and false if
+// the media type does not identify one of the supported formats.
+func FormatFromMimetype(mimetype string) (Format, bool) {
+	mimetype = strings.ToLower(strings.TrimSpace(mimetype))
+	switch mimetype {
+	case "application/json", "text/json":
+		return FormatJSON, true
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML, true
+	case "application/toml", "text/toml":
+		return FormatTOML, true
+	case "text/csv":
+		return FormatCSV, true
+	case "application/xml", "text/xml":
+		return FormatXML, true
+	}
+	switch {
+	case strings.HasSuffix(mimetype, "+json"):
+		return FormatJSON, true
+	case strings.HasSuffix(mimetype, "+yaml"):
+		return FormatYAML, true
+	case strings.HasSuffix(mimetype, "+xml"):
+		return FormatXML, true
+	}
+	return "", false
+}
+
+// tomlHintRe matches the first meaningful line of a TOML document: a table header or a key assignment.
+var tomlHintRe = regexp.MustCompile(`^(\[\[?[\w."' -]+\]\]?|[\w."'-]+\s*=)`)
+
+// SniffFormat guesses the data format from content for inputs without a usable extension or media type.
+// JSON and XML are recognized from their first character, TOML from its first meaningful line (and only
+// if the whole document parses), and anything else is reported as YAML.
+func SniffFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return FormatYAML
+	}
+	switch trimmed[0] {
+	case '{', '[':
+		if json.Valid(trimmed) {
+			return FormatJSON
+		}
+	case '<':
+		return FormatXML
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if tomlHintRe.MatchString(line) {
+			var v map[string]any
+			if toml.Unmarshal(trimmed, &v) == nil {
+				return FormatTOML
+			}
+		}
+		break
+	}
+	return FormatYAML
+}
+
+// Marshal encodes v in the given format, only YAML, JSON and TOML are supported for output. The output always ends with a newline.
+// Whole-number floats (as produced by JSON decoding of --set values and schema defaults) are written
+// as integers so that YAML and TOML output matches the JSON output. A TOML document is a table, so
+// only maps can be written as TOML.
+func Marshal(v any, format Format) ([]byte, error) {
+	var out []byte
+	var err error
+	switch format {
+	case FormatYAML:
+		out, err = yaml.Marshal(integralFloatsToInts(v))
+	case FormatJSON:
+		out, err = json.MarshalIndent(v, "", "  ")
+	case FormatTOML:
+		if _, ok := v.(map[string]any); !ok {
+			return nil, fmt.Errorf("unable to encode data as %s: a TOML document must be a map, got %T", format, v)
+		}
+		out, err = toml.Marshal(integralFloatsToInts(v))
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode data as %s: %w", format, err)
+	}
+	if len(out) == 0 || out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
+
+// integralFloatsToInts returns a copy of v with every float64 that holds a whole number replaced by an int64.
+func integralFloatsToInts(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = integralFloatsToInts(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = integralFloatsToInts(item)
+		}
+		return out
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Format
+		expectError bool
+	}{
+		{input: "yaml", expected: FormatYAML},
+		{input: "YML", expected: FormatYAML},
+		{input: "json", expected: FormatJSON},
+		{input: " toml ", expected: FormatTOML},
+		{input: "tfvars", expected: FormatHCL},
+		{input: "dotenv", expected: FormatEnv},
+		{input: "csv", expected: FormatCSV},
+		{input: "", expectError: true},
+		{input: "docx", expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			f, err := ParseFormat(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, f)
+		})
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	f, err := ParseOutputFormat("TOML")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatTOML, f)
+
+	_, err = ParseOutputFormat("csv")
+	assert.EqualError(t, err, "unsupported output format: csv, must be one of: yaml, json, toml")
+
+	_, err = ParseOutputFormat("docx")
+	assert.EqualError(t, err, "invalid format: docx")
+}
+
+func TestFormatFromExtension(t *testing.T) {
+	tests := map[string]Format{
+		"values.yml":             FormatYAML,
+		"values.JSON":            FormatJSON,
+		"prod.tfvars":            FormatHCL,
+		"settings.ini":           FormatINI,
+		".env":                   FormatEnv,
+		"inventory.csv":          FormatCSV,
+		"pom.xml":                FormatXML,
+		"archive.tgz#values.xml": FormatXML,
+	}
+	for name, expected := range tests {
+		format, ok := FormatFromExtension(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expected, format, name)
+	}
+
+	_, ok := FormatFromExtension("data.txt")
+	assert.False(t, ok)
+}
+
+func TestMarshal(t *testing.T) {
+	v := map[string]any{"a": map[string]any{"b": 1}}
+	tests := []struct {
+		format   Format
+		expected string
+	}{
+		{format: FormatYAML, expected: "a:\n  b: 1\n"},
+		{format: FormatJSON, expected: "{\n  \"a\": {\n    \"b\": 1\n  }\n}\n"},
+		{format: FormatTOML, expected: "[a]\nb = 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			out, err := Marshal(v, tt.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+		})
+	}
+
+	_, err := Marshal(v, Format("xml"))
+	assert.Error(t, err)
+
+	_, err = Marshal(1, FormatTOML)
+	assert.ErrorContains(t, err, "a TOML document must be a map, got int")
+}
+
+func TestFormatFromMimetype(t *testing.T) {
+	tests := map[string]Format{
+		"application/json":             FormatJSON,
+		"application/vnd.api+json":     FormatJSON,
+		"application/yaml":             FormatYAML,
+		"text/x-yaml":                  FormatYAML,
+		"application/toml":             FormatTOML,
+		"text/csv":                     FormatCSV,
+		"text/xml":                     FormatXML,
+		"application/atom+xml":         FormatXML,
+		" Application/JSON ":           FormatJSON,
+		"application/vnd.foo+yaml":     FormatYAML,
+		"application/problem+json":     FormatJSON,
+		"application/rss+xml":          FormatXML,
+		"application/x-yaml":           FormatYAML,
+		"text/json":                    FormatJSON,
+		"text/toml":                    FormatTOML,
+		"text/yaml":                    FormatYAML,
+		"application/xml":              FormatXML,
+		"application/merge-patch+json": FormatJSON,
+	}
+	for mimetype, expected := range tests {
+		format, ok := FormatFromMimetype(mimetype)
+		assert.True(t, ok, mimetype)
+		assert.Equal(t, expected, format, mimetype)
+	}
+
+	for _, mimetype := range []string{"text/plain", "application/octet-stream", ""} {
+		_, ok := FormatFromMimetype(mimetype)
+		assert.False(t, ok, mimetype)
+	}
+}
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Format
+	}{
+		{name: "json object", content: "  {\"a\": 1}\n", expected: FormatJSON},
+		{name: "json array", content: "[1, 2]", expected: FormatJSON},
+		{name: "yaml flow mapping", content: "{a: 1}", expected: FormatYAML},
+		{name: "xml", content: "<?xml version=\"1.0\"?><a/>", expected: FormatXML},
+		{name: "toml table", content: "# comment\n[server]\nport = 80\n", expected: FormatTOML},
+		{name: "toml key", content: "name = \"app\"\n", expected: FormatTOML},
+		{name: "not toml", content: "name = app\n", expected: FormatYAML},
+		{name: "yaml", content: "name: app\nport: 80\n", expected: FormatYAML},
+		{name: "empty", content: "", expected: FormatYAML},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SniffFormat([]byte(tt.content)))
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package data
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adam-huganir/yutc/pkg/schema"
+	"github.com/theory/jsonpath/spec"
+)
+
+// MissingValue is a value required by a schema that is missing from the merged data.
+type MissingValue struct {
+	Location spec.NormalizedPath // location of the value in the merged data
+	schema.Field
+}
+
+// Pointer returns the JSON pointer to the value in the merged data.
+func (m MissingValue) Pointer() string {
+	return SchemaViolation{Location: m.Location}.Pointer()
+}
+
+// PromptFunc asks for the values missing from the data validated by a schema and returns one value for
+// each of them, in order.
+type PromptFunc func(schemaName string, missing []MissingValue) ([]any, error)
+
+// MissingValuesError lists the required values that are missing when they can't be prompted for.
+type MissingValuesError struct {
+	Schema  string // name of the schema input
+	Missing []MissingValue
+}
+
+func (e *MissingValuesError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unable to prompt for %d missing required value(s) of schema %s, stdin is not a terminal", len(e.Missing), e.Schema)
+	for _, m := range e.Missing {
+		b.WriteString("\n  " + m.Pointer())
+		if m.Description != "" {
+			b.WriteString(": " + m.Description)
+		} else if m.Title != "" {
+			b.WriteString(": " + m.Title)
+		}
+	}
+	return b.String()
+}
+
+// NoPrompt is the PromptFunc of non-interactive runs. Missing values are set to their default, and if any
+// have none it fails with a *MissingValuesError listing them.
+func NoPrompt(schemaName string, missing []MissingValue) ([]any, error) {
+	values := make([]any, len(missing))
+	var noDefault []MissingValue
+	for i, m := range missing {
+		if !m.HasDefault {
+			noDefault = append(noDefault, m)
+		}
+		values[i] = m.Default
+	}
+	if len(noDefault) > 0 {
+		return nil, &MissingValuesError{Schema: schemaName, Missing: noDefault}
+	}
+	return values, nil
+}
+
+// TerminalPrompt returns a PromptFunc that asks for each value on out and reads the answers from in, one
+// per line. Answers are checked against the type and enum of the value and asked again until they match,
+// an empty answer selects the default.
+func TerminalPrompt(in io.Reader, out io.Writer) PromptFunc {
+	reader := bufio.NewReader(in)
+	return func(schemaName string, missing []MissingValue) ([]any, error) {
+		if _, err := fmt.Fprintf(out, "Schema %s requires %d missing value(s)\n", schemaName, len(missing)); err != nil {
+			return nil, err
+		}
+		values := make([]any, 0, len(missing))
+		for _, m := range missing {
+			for {
+				if _, err := io.WriteString(out, promptLine(m)); err != nil {
+					return nil, err
+				}
+				line, err := reader.ReadString('\n')
+				if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+					return nil, fmt.Errorf("unable to read value for %s: %w", m.Pointer(), err)
+				}
+				value, parseErr := parseAnswer(strings.TrimSpace(line), m.Field)
+				if parseErr == nil {
+					values = append(values, value)
+					break
+				}
+				if errors.Is(err, io.EOF) {
+					return nil, fmt.Errorf("invalid value for %s: %w", m.Pointer(), parseErr)
+				}
+				if _, err = fmt.Fprintf(out, "  invalid value: %v\n", parseErr); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return values, nil
+	}
+}
+
+// promptLine formats the question for a missing value, ex: `/license: License to use (one of: MIT, ISC) [MIT]: `.
+func promptLine(m MissingValue) string {
+	var b strings.Builder
+	b.WriteString(m.Pointer())
+	switch {
+	case m.Description != "":
+		b.WriteString(": " + m.Description)
+	case m.Title != "":
+		b.WriteString(": " + m.Title)
+	}
+	if len(m.Enum) > 0 {
+		choices := make([]string, len(m.Enum))
+		for i, value := range m.Enum {
+			choices[i] = answerString(value)
+		}
+		b.WriteString(" (one of: " + strings.Join(choices, ", ") + ")")
+	} else if len(m.Types) > 0 {
+		b.WriteString(" (" + strings.Join(m.Types, " or ") + ")")
+	}
+	if m.HasDefault {
+		b.WriteString(" [" + answerString(m.Default) + "]")
+	}
+	b.WriteString(": ")
+	return b.String()
+}
+
+// answerString formats a value the way it is entered: strings as is and anything else as JSON.
+func answerString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+// parseAnswer converts an answer to a value of the field. Enum values are matched as they are displayed,
+// otherwise the answer is parsed as the first of the field's types it is valid for, strings last. Answers for
+// fields without a type are parsed as JSON if possible, as with --set.
+func parseAnswer(answer string, field schema.Field) (any, error) {
+	if answer == "" {
+		if field.HasDefault {
+			return field.Default, nil
+		}
+		return nil, errors.New("a value is required")
+	}
+	if len(field.Enum) > 0 {
+		choices := make([]string, len(field.Enum))
+		for i, value := range field.Enum {
+			choices[i] = answerString(value)
+			if choices[i] == answer {
+				return value, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of: %s", strings.Join(choices, ", "))
+	}
+	if len(field.Types) == 0 {
+		var value any
+		if err := json.Unmarshal([]byte(answer), &value); err != nil {
+			return answer, nil
+		}
+		return value, nil
+	}
+	for _, typ := range field.Types {
+		switch typ {
+		case "null":
+			if answer == "null" {
+				return nil, nil
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(answer); err == nil {
+				return b, nil
+			}
+		case "integer":
+			if i, err := strconv.ParseInt(answer, 10, 64); err == nil {
+				return i, nil
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(answer, 64); err == nil {
+				return f, nil
+			}
+		case "array":
+			var value []any
+			if err := json.Unmarshal([]byte(answer), &value); err == nil && value != nil {
+				return value, nil
+			}
+		case "object":
+			var value map[string]any
+			if err := json.Unmarshal([]byte(answer), &value); err == nil && value != nil {
+				return value, nil
+			}
+		}
+	}
+	if slices.Contains(field.Types, "string") {
+		return answer, nil
+	}
+	return nil, fmt.Errorf("expected %s", strings.Join(field.Types, " or "))
+}
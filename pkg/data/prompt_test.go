@@ -0,0 +1,93 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		field    schema.Field
+		expected any
+		err      string
+	}{
+		{name: "string", answer: "123", field: schema.Field{Types: []string{"string"}}, expected: "123"},
+		{name: "integer", answer: "42", field: schema.Field{Types: []string{"integer"}}, expected: int64(42)},
+		{name: "invalid integer", answer: "4.2", field: schema.Field{Types: []string{"integer"}}, err: "expected integer"},
+		{name: "number", answer: "4.2", field: schema.Field{Types: []string{"number"}}, expected: 4.2},
+		{name: "boolean", answer: "true", field: schema.Field{Types: []string{"boolean"}}, expected: true},
+		{name: "boolean or string", answer: "maybe", field: schema.Field{Types: []string{"boolean", "string"}}, expected: "maybe"},
+		{name: "array", answer: `["a", 1]`, field: schema.Field{Types: []string{"array"}}, expected: []any{"a", float64(1)}},
+		{name: "object is not an array", answer: `{"a": 1}`, field: schema.Field{Types: []string{"array"}}, err: "expected array"},
+		{name: "object", answer: `{"a": 1}`, field: schema.Field{Types: []string{"object"}}, expected: map[string]any{"a": float64(1)}},
+		{name: "untyped json", answer: "[1]", field: schema.Field{}, expected: []any{float64(1)}},
+		{name: "untyped string", answer: "hello world", field: schema.Field{}, expected: "hello world"},
+		{name: "enum", answer: "2", field: schema.Field{Enum: []any{"MIT", float64(2)}}, expected: float64(2)},
+		{name: "not in enum", answer: "GPL", field: schema.Field{Enum: []any{"MIT", "ISC"}}, err: "must be one of: MIT, ISC"},
+		{name: "default", answer: "", field: schema.Field{Default: "MIT", HasDefault: true}, expected: "MIT"},
+		{name: "empty without default", answer: "", field: schema.Field{Types: []string{"string"}}, err: "a value is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseAnswer(tt.answer, tt.field)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestTerminalPrompt(t *testing.T) {
+	missing := []MissingValue{
+		{
+			Location: spec.Normalized(spec.Name("port")),
+			Field:    schema.Field{Path: []string{"port"}, Description: "Port to listen on", Types: []string{"integer"}},
+		},
+		{
+			Location: spec.Normalized(spec.Name("license")),
+			Field:    schema.Field{Path: []string{"license"}, Enum: []any{"MIT", "ISC"}, Default: "MIT", HasDefault: true},
+		},
+	}
+	var out bytes.Buffer
+	prompt := TerminalPrompt(strings.NewReader("http\n8080\n\n"), &out)
+
+	values, err := prompt("schema.yaml", missing)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{int64(8080), "MIT"}, values)
+	assert.Equal(t, "Schema schema.yaml requires 2 missing value(s)\n"+
+		"/port: Port to listen on (integer): "+
+		"  invalid value: expected integer\n"+
+		"/port: Port to listen on (integer): "+
+		"/license (one of: MIT, ISC) [MIT]: ", out.String())
+
+	_, err = TerminalPrompt(strings.NewReader(""), &out)("schema.yaml", missing)
+	assert.ErrorContains(t, err, "unable to read value for /port: EOF")
+}
+
+func TestNoPrompt(t *testing.T) {
+	withDefault := MissingValue{
+		Location: spec.Normalized(spec.Name("python")),
+		Field:    schema.Field{Path: []string{"python"}, Default: "3.12", HasDefault: true},
+	}
+	values, err := NoPrompt("schema.yaml", []MissingValue{withDefault})
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"3.12"}, values)
+
+	noDefault := MissingValue{
+		Location: spec.Normalized(spec.Name("name")),
+		Field:    schema.Field{Path: []string{"name"}, Description: "Name of the project"},
+	}
+	_, err = NoPrompt("schema.yaml", []MissingValue{withDefault, noDefault})
+	assert.EqualError(t, err, "unable to prompt for 1 missing required value(s) of schema schema.yaml, stdin is not a terminal\n  /name: Name of the project")
+}
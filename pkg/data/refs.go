@@ -0,0 +1,291 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
+)
+
+// refRe matches "${.path}", "${$.path}" and "${env:VAR}" references, and the "$${" escape for a literal "${".
+var refRe = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+
+// refResolver expands references in string values of merged data.
+type refResolver struct {
+	data   map[string]any
+	origin func(location spec.NormalizedPath) string
+	done   map[string]bool
+	stack  []spec.NormalizedPath // string values currently being resolved, for cycle detection
+}
+
+// ResolveRefs expands "${.path.to.value}" references to other values and "${env:VAR}" references to
+// environment variables within string values of the merged data, in place. A string that is exactly one
+// path reference is replaced by the referenced value with its type, otherwise references are interpolated
+// as text. References may point to values that contain references, cycles are an error. "$${" is an
+// escape for a literal "${". Errors name the data file (or --set value) the failing value came from.
+func ResolveRefs(merged map[string]any, dataFiles []*Input, setArgs []SetArg) error {
+	r := &refResolver{
+		data:   merged,
+		origin: func(location spec.NormalizedPath) string { return Origin(dataFiles, setArgs, location) },
+		done:   make(map[string]bool),
+	}
+	_, err := r.resolveTree(merged, spec.Normalized())
+	return err
+}
+
+// resolveTree resolves every string below v, returning the (possibly replaced) value.
+func (r *refResolver) resolveTree(v any, location spec.NormalizedPath) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			resolved, err := r.resolveTree(v[key], appendPath(location, spec.Name(key)))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			resolved, err := r.resolveTree(item, appendPath(location, spec.Index(i)))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	case string:
+		return r.resolveString(v, location)
+	default:
+		return v, nil
+	}
+}
+
+func (r *refResolver) resolveString(s string, location spec.NormalizedPath) (any, error) {
+	key := location.String()
+	if r.done[key] || !strings.Contains(s, "${") {
+		return s, nil
+	}
+	for i, inProgress := range r.stack {
+		if inProgress.Compare(location) == 0 {
+			chain := make([]string, 0, len(r.stack)-i+1)
+			for _, p := range r.stack[i:] {
+				chain = append(chain, p.String())
+			}
+			chain = append(chain, key)
+			return nil, fmt.Errorf("reference cycle %s%s", strings.Join(chain, " -> "), r.describeOrigin(location))
+		}
+	}
+	r.stack = append(r.stack, location)
+	defer func() { r.stack = r.stack[:len(r.stack)-1] }()
+
+	matches := refRe.FindAllStringSubmatchIndex(s, -1)
+	// a string that is exactly one path reference keeps the type of the referenced value
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) && matches[0][2] >= 0 {
+		ref := s[matches[0][2]:matches[0][3]]
+		if !strings.HasPrefix(ref, "env:") {
+			value, err := r.lookup(ref, location)
+			if err != nil {
+				return nil, err
+			}
+			r.done[key] = true
+			return value, nil
+		}
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, m := range matches {
+		out.WriteString(s[last:m[0]])
+		last = m[1]
+		if m[2] < 0 {
+			out.WriteString("${")
+			continue
+		}
+		ref := s[m[2]:m[3]]
+		var value any
+		if name, ok := strings.CutPrefix(ref, "env:"); ok {
+			envValue, set := os.LookupEnv(name)
+			if !set {
+				return nil, fmt.Errorf("unable to resolve ${%s} in %s%s: environment variable %s is not set",
+					ref, key, r.describeOrigin(location), name)
+			}
+			value = envValue
+		} else {
+			var err error
+			if value, err = r.lookup(ref, location); err != nil {
+				return nil, err
+			}
+		}
+		text, err := refText(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve ${%s} in %s%s: %w", ref, key, r.describeOrigin(location), err)
+		}
+		out.WriteString(text)
+	}
+	out.WriteString(s[last:])
+	r.done[key] = true
+	return out.String(), nil
+}
+
+// lookup returns the fully resolved value referenced by ref from a string at location.
+func (r *refResolver) lookup(ref string, location spec.NormalizedPath) (any, error) {
+	fail := func(err error) error {
+		return fmt.Errorf("unable to resolve ${%s} in %s%s: %w", ref, location, r.describeOrigin(location), err)
+	}
+	pathExpr := strings.TrimSpace(ref)
+	if strings.HasPrefix(pathExpr, ".") || strings.HasPrefix(pathExpr, "[") {
+		pathExpr = "$" + pathExpr
+	}
+	parsed, err := jsonpath.Parse(pathExpr)
+	if err != nil {
+		return nil, fail(err)
+	}
+	if parsed.Query().Singular() == nil {
+		return nil, fail(fmt.Errorf("%s is not a singular path", pathExpr))
+	}
+	located := parsed.SelectLocated(r.data)
+	if len(located) == 0 {
+		return nil, fail(fmt.Errorf("%s not found", pathExpr))
+	}
+	target := located[0].Path
+	resolved, err := r.resolveTree(located[0].Node, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(target) > 0 {
+		setAtPath(r.data, target, resolved)
+	}
+	return resolved, nil
+}
+
+func (r *refResolver) describeOrigin(location spec.NormalizedPath) string {
+	if origin := r.origin(location); origin != "" {
+		return " (from " + origin + ")"
+	}
+	return ""
+}
+
+// refText formats a referenced value for interpolation into a string.
+func refText(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", nil
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Origin returns a description of the input that last set the value at location in the merged data: the
+// --set flag or data file name, or "" if it is unknown (ex: a schema default).
+func Origin(dataFiles []*Input, setArgs []SetArg, location spec.NormalizedPath) string {
+	for i := len(setArgs) - 1; i >= 0; i-- {
+		if setArgs[i].Kind == SetKindUnset {
+			continue
+		}
+		pathExpr, _, _, err := setArgs[i].Parse()
+		if err != nil {
+			continue
+		}
+		parsed, err := jsonpath.Parse(pathExpr)
+		if err != nil || parsed.Query().Singular() == nil {
+			continue
+		}
+		setPath := normalizedPrefix(parsed)
+		if len(setPath) <= len(location) && setPath.Compare(location[:len(setPath)]) == 0 {
+			return setArgs[i].String()
+		}
+	}
+	for i := len(dataFiles) - 1; i >= 0; i-- {
+		if dataFiles[i].contribution == nil {
+			continue
+		}
+		if _, ok := getAtPath(dataFiles[i].contribution, location); ok {
+			return dataFiles[i].Name
+		}
+	}
+	return ""
+}
+
+func getAtPath(v any, location spec.NormalizedPath) (any, bool) {
+	current := v
+	for _, selector := range location {
+		switch sel := selector.(type) {
+		case spec.Name:
+			m, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = m[string(sel)]; !ok {
+				return nil, false
+			}
+		case spec.Index:
+			arr, ok := current.([]any)
+			if !ok || int(sel) < 0 || int(sel) >= len(arr) {
+				return nil, false
+			}
+			current = arr[sel]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setAtPath(root map[string]any, location spec.NormalizedPath, value any) {
+	parent, ok := getAtPath(root, location[:len(location)-1])
+	if !ok {
+		return
+	}
+	switch sel := location[len(location)-1].(type) {
+	case spec.Name:
+		if m, ok := parent.(map[string]any); ok {
+			m[string(sel)] = value
+		}
+	case spec.Index:
+		if arr, ok := parent.([]any); ok && int(sel) < len(arr) {
+			arr[sel] = value
+		}
+	}
+}
+
+func appendPath(location spec.NormalizedPath, selector spec.NormalSelector) spec.NormalizedPath {
+	return append(location[:len(location):len(location)], selector)
+}
+
+// copyData returns a deep copy of decoded data.
+func copyData(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = copyData(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = copyData(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,107 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestResolveRefs(t *testing.T) {
+	t.Setenv("YUTC_TEST_REGION", "eu-west-1")
+	tests := []struct {
+		name        string
+		data        map[string]any
+		expected    map[string]any
+		expectError string
+	}{
+		{
+			name: "interpolated and typed references",
+			data: map[string]any{
+				"domain": "example.com",
+				"port":   float64(8443),
+				"api":    map[string]any{"host": "api.${.domain}", "url": "https://${.api.host}:${.port}"},
+				"tls":    map[string]any{"port": "${.port}", "hosts": "${$.api}"},
+			},
+			expected: map[string]any{
+				"domain": "example.com",
+				"port":   float64(8443),
+				"api":    map[string]any{"host": "api.example.com", "url": "https://api.example.com:8443"},
+				"tls": map[string]any{
+					"port":  float64(8443),
+					"hosts": map[string]any{"host": "api.example.com", "url": "https://api.example.com:8443"},
+				},
+			},
+		},
+		{
+			name:     "env and list references",
+			data:     map[string]any{"zones": []any{"a", "b"}, "zone": "${env:YUTC_TEST_REGION}${.zones[1]}"},
+			expected: map[string]any{"zones": []any{"a", "b"}, "zone": "eu-west-1b"},
+		},
+		{
+			name:     "escaped reference",
+			data:     map[string]any{"a": "1", "literal": "$${.a} is ${.a}"},
+			expected: map[string]any{"a": "1", "literal": "${.a} is 1"},
+		},
+		{
+			name:        "cycle",
+			data:        map[string]any{"a": "${.b}", "b": "x${.c}", "c": "${.a}"},
+			expectError: "reference cycle $['a'] -> $['b'] -> $['c'] -> $['a']",
+		},
+		{
+			name:        "reference to a parent",
+			data:        map[string]any{"a": map[string]any{"b": "${.a}"}},
+			expectError: "reference cycle $['a']['b'] -> $['a']['b']",
+		},
+		{
+			name:        "missing path",
+			data:        map[string]any{"a": "${.nope}"},
+			expectError: "unable to resolve ${.nope} in $['a']: $.nope not found",
+		},
+		{
+			name:        "missing env",
+			data:        map[string]any{"a": "${env:YUTC_TEST_NOT_SET}"},
+			expectError: "environment variable YUTC_TEST_NOT_SET is not set",
+		},
+		{
+			name:        "not singular",
+			data:        map[string]any{"a": "${.b[*]}", "b": []any{"x"}},
+			expectError: "$.b[*] is not a singular path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveRefs(tt.data, nil, nil)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.data)
+		})
+	}
+}
+
+func TestResolveRefs_ErrorNamesOrigin(t *testing.T) {
+	tmpDir := t.TempDir()
+	base := filepath.Join(tmpDir, "base.yaml")
+	prod := filepath.Join(tmpDir, "prod.yaml")
+	assert.NoError(t, os.WriteFile(base, []byte("host: ${.domain}\nname: app\n"), 0o644))
+	assert.NoError(t, os.WriteFile(prod, []byte("db:\n  host: db.${.domian}\n"), 0o644))
+
+	logger := zerolog.Nop()
+	dataFiles := []*Input{NewInput(base, nil), NewInput(prod, nil)}
+	setArgs := NewSetArgs(SetKindValue, []string{".domain=example.com", ".url=https://${.nope}"})
+	merged, err := MergeDataFiles(dataFiles, setArgs[:1], false, &logger)
+	assert.NoError(t, err)
+
+	err = ResolveRefs(merged, dataFiles, setArgs[:1])
+	assert.ErrorContains(t, err, "unable to resolve ${.domian} in $['db']['host'] (from "+dataFiles[1].Name+"): $.domian not found")
+
+	assert.Equal(t, dataFiles[0].Name, Origin(dataFiles, setArgs, spec.Normalized(spec.Name("name"))))
+	assert.Equal(t, "--set .url=https://${.nope}", Origin(dataFiles, setArgs, spec.Normalized(spec.Name("url"))))
+	assert.Equal(t, "", Origin(dataFiles, setArgs, spec.Normalized(spec.Name("missing"))))
+}
@@ -0,0 +1,198 @@
+package data
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/adam-huganir/yutc/pkg/schema"
+	"github.com/theory/jsonpath/spec"
+)
+
+// SchemaError lists every way the merged data does not match a schema.
+type SchemaError struct {
+	Schema     string // name of the schema input
+	Violations []SchemaViolation
+}
+
+// SchemaViolation is a single schema violation located in the merged data.
+type SchemaViolation struct {
+	Location spec.NormalizedPath // location of the invalid value in the merged data
+	Message  string
+	Origin   string // the data file or --set flag that supplied the value, "" if unknown
+}
+
+// Pointer returns the JSON pointer to the invalid value, "/" for the root.
+func (v SchemaViolation) Pointer() string {
+	if len(v.Location) == 0 {
+		return "/"
+	}
+	return v.Location.Pointer()
+}
+
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "unable to validate schema %s: %d error(s)", e.Schema, len(e.Violations))
+	for _, v := range e.Violations {
+		b.WriteString("\n  " + v.Pointer() + ": " + v.Message)
+		if v.Origin != "" {
+			b.WriteString(" (from " + v.Origin + ")")
+		}
+	}
+	return b.String()
+}
+
+// schemaURL returns the absolute URL that relative $refs in this schema are resolved against.
+func (di *Input) schemaURL() (string, error) {
+	if di.Source == loader.SourceKindURL {
+		if di.Remote.URL != nil {
+			return di.Remote.URL.String(), nil
+		}
+		return di.Name, nil
+	}
+	path := di.Name
+	switch di.Source {
+	case loader.SourceKindFile, loader.SourceKindGit:
+		ioPath, err := di.IOPath()
+		if err != nil {
+			return "", err
+		}
+		path = ioPath
+	default:
+		// refs from stdin are relative to the working directory
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(wd, "schema")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	abs = filepath.ToSlash(abs)
+	if !strings.HasPrefix(abs, "/") {
+		abs = "/" + abs // windows drive paths
+	}
+	return (&url.URL{Scheme: "file", Path: abs}).String(), nil
+}
+
+// loadSchemaRef loads a schema referenced with $ref through a FileEntry. Remote schemas are fetched with
+// the auth of the schema that references them only if they are on the same host, and a remote schema
+// cannot reference local files.
+func (di *Input) loadSchemaRef(location string) (any, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	base, err := di.schemaURL()
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil, err
+	}
+	var entryOpts []loader.FileEntryOption
+	name := location
+	switch u.Scheme {
+	case "file":
+		if baseURL.Scheme != "file" {
+			return nil, fmt.Errorf("unable to load $ref %s: remote schema %s cannot reference local files", location, di.Name)
+		}
+		name = filepath.FromSlash(u.Path)
+		if len(name) > 2 && name[0] == filepath.Separator && name[2] == ':' {
+			name = name[1:] // windows drive paths
+		}
+		entryOpts = append(entryOpts, loader.WithSource(loader.SourceKindFile))
+	case "http", "https":
+		entryOpts = append(entryOpts, loader.WithSource(loader.SourceKindURL))
+		if u.Host == baseURL.Host {
+			entryOpts = append(entryOpts, loader.WithAuth(di.Auth))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported $ref location %s", location)
+	}
+	entryOpts = append(entryOpts, loader.WithLogger(di.Logger()))
+	di.Logger().Debug().Msgf("Loading schema %s referenced from %s", location, di.Name)
+
+	ref := NewInput(name, entryOpts)
+	if err = ref.Load(); err != nil {
+		return nil, err
+	}
+	return decodeData(ref.Name, ref.Content.Data, ref.DataFormat())
+}
+
+// promptMissing asks for the required values missing from target, located at prefix in the merged data,
+// and sets them.
+func (di *Input) promptMissing(compiled *schema.Compiled, target any, prefix spec.NormalizedPath) error {
+	obj, ok := target.(map[string]any)
+	if !ok {
+		return nil
+	}
+	fields := compiled.MissingRequired(obj)
+	if len(fields) == 0 {
+		return nil
+	}
+	missing := make([]MissingValue, len(fields))
+	for i, field := range fields {
+		location := slices.Clone(prefix)
+		for _, key := range field.Path {
+			location = append(location, spec.Name(key))
+		}
+		missing[i] = MissingValue{Location: location, Field: field}
+	}
+	values, err := di.Schema.Prompt(di.Name, missing)
+	if err != nil {
+		return err
+	}
+	for i, field := range fields {
+		current := obj
+		for _, key := range field.Path[:len(field.Path)-1] {
+			next, ok := current[key].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				current[key] = next
+			}
+			current = next
+		}
+		current[field.Path[len(field.Path)-1]] = values[i]
+	}
+	return nil
+}
+
+// pointerLocation converts a JSON pointer into v to a normalized path, using v to tell array indexes
+// from object keys.
+func pointerLocation(v any, pointer string) spec.NormalizedPath {
+	location := spec.Normalized()
+	if pointer == "" {
+		return location
+	}
+	current := v
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if arr, ok := current.([]any); ok {
+			if idx, err := strconv.Atoi(token); err == nil {
+				location = append(location, spec.Index(idx))
+				if idx >= 0 && idx < len(arr) {
+					current = arr[idx]
+				} else {
+					current = nil
+				}
+				continue
+			}
+		}
+		location = append(location, spec.Name(token))
+		if m, ok := current.(map[string]any); ok {
+			current = m[token]
+		} else {
+			current = nil
+		}
+	}
+	return location
+}
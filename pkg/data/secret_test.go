@@ -0,0 +1,184 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
+)
+
+const secretsDir = "../../testFiles/secrets"
+
+func readSecretFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(secretsDir, name))
+	assert.NoError(t, err)
+	return b
+}
+
+// The fixtures are encrypted by sops 3.13.3 with testFiles/secrets/generate.sh.
+func TestDecryptSOPS(t *testing.T) {
+	ageKey := SecretInfo{KeyFile: filepath.Join(secretsDir, "age-test-key.txt")}
+	tests := []struct {
+		name          string
+		file          string
+		format        Format
+		info          SecretInfo
+		expected      map[string]any
+		expectedPaths []string
+		expectError   string
+	}{
+		{
+			name:   "age yaml with encrypted_regex",
+			file:   "secrets.sops.yaml",
+			format: FormatYAML,
+			info:   ageKey,
+			expected: map[string]any{
+				"database": map[string]any{"host": "db.internal", "password": "hunter2", "port": uint64(5432)},
+				"api_keys": []any{map[string]any{"name": "payments", "token": "tok-123"}},
+				"matrix": []any{
+					[]any{"a", "b"},
+					[]any{map[string]any{"name": "nested", "password": "deep-secret"}},
+				},
+				"enabled": true,
+				"ratio":   0.5,
+			},
+			expectedPaths: []string{
+				"$['database']['password']", "$['api_keys'][0]['token']", "$['matrix'][1][0]['password']",
+			},
+		},
+		{
+			name:   "age json with typed values",
+			file:   "secrets.sops.json",
+			format: FormatJSON,
+			info:   ageKey,
+			expected: map[string]any{
+				"db":      map[string]any{"user": "admin", "password": "s3cret"},
+				"retries": 3,
+				"hosts":   []any{[]any{"a", "b"}, []any{map[string]any{"port": 5432}}},
+			},
+			expectedPaths: []string{
+				"$['db']['user']", "$['db']['password']", "$['retries']",
+				"$['hosts'][0][0]", "$['hosts'][0][1]", "$['hosts'][1][0]['port']",
+			},
+		},
+		{
+			name:          "pgp key",
+			file:          "pgp.sops.yaml",
+			format:        FormatYAML,
+			info:          SecretInfo{KeyFile: filepath.Join(secretsDir, "pgp-test-key.asc")},
+			expected:      map[string]any{"smtp": map[string]any{"password": "pgp-pass"}},
+			expectedPaths: []string{"$['smtp']['password']"},
+		},
+		{
+			name:   "mac only encrypted",
+			file:   "mac-only.sops.yaml",
+			format: FormatYAML,
+			info:   ageKey,
+			expected: map[string]any{
+				"plain": "visible",
+				"api":   map[string]any{"db_secret": "abc", "count": uint64(7)},
+			},
+			expectedPaths: []string{"$['api']['db_secret']"},
+		},
+		{
+			name:        "wrong key",
+			file:        "pgp.sops.yaml",
+			format:      FormatYAML,
+			info:        ageKey,
+			expectError: "none of the 0 age and 1 pgp recipients match the available keys",
+		},
+		{
+			name:        "not a sops file",
+			file:        "../data/data1.yaml",
+			format:      FormatYAML,
+			info:        ageKey,
+			expectError: "missing sops metadata",
+		},
+		{
+			name:        "unsupported format",
+			file:        "secrets.sops.yaml",
+			format:      FormatTOML,
+			info:        ageKey,
+			expectError: "only yaml and json sops files are supported, not toml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decrypted, paths, err := decryptSOPS(readSecretFixture(t, tt.file), tt.format, tt.info)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, decrypted)
+			actualPaths := make([]string, 0, len(paths))
+			for _, p := range paths {
+				actualPaths = append(actualPaths, p.String())
+			}
+			assert.ElementsMatch(t, tt.expectedPaths, actualPaths)
+		})
+	}
+}
+
+func TestDecryptSOPS_Tampered(t *testing.T) {
+	ageKey := SecretInfo{KeyFile: filepath.Join(secretsDir, "age-test-key.txt")}
+	content := string(readSecretFixture(t, "secrets.sops.yaml"))
+
+	_, _, err := decryptSOPS([]byte(strings.Replace(content, "host: db.internal", "host: evil.internal", 1)), FormatYAML, ageKey)
+	assert.ErrorContains(t, err, "sops mac mismatch")
+
+	_, _, err = decryptSOPS([]byte(strings.Replace(content, "database:", "databases:", 1)), FormatYAML, ageKey)
+	assert.ErrorContains(t, err, "unable to decrypt $['databases']['password']: authentication failed")
+}
+
+func TestDecryptSOPS_KeyFromEnv(t *testing.T) {
+	key := readSecretFixture(t, "age-test-key.txt")
+
+	t.Setenv("YUTC_TEST_AGE_KEY", string(key))
+	_, _, err := decryptSOPS(readSecretFixture(t, "secrets.sops.yaml"), FormatYAML, SecretInfo{KeyEnv: "YUTC_TEST_AGE_KEY"})
+	assert.NoError(t, err)
+
+	t.Setenv(sopsAgeKeyEnv, string(key))
+	_, _, err = decryptSOPS(readSecretFixture(t, "secrets.sops.yaml"), FormatYAML, SecretInfo{})
+	assert.NoError(t, err)
+
+	_, _, err = decryptSOPS(readSecretFixture(t, "secrets.sops.yaml"), FormatYAML, SecretInfo{KeyEnv: "YUTC_TEST_UNSET_KEY"})
+	assert.ErrorContains(t, err, "key environment variable YUTC_TEST_UNSET_KEY is not set")
+}
+
+func TestMergeDataFiles_Secret(t *testing.T) {
+	secret := NewInput(filepath.Join(secretsDir, "secrets.sops.yaml"), nil,
+		WithJSONPath(jsonpath.MustParse("$.secrets")),
+		AsSecret(SecretInfo{KeyFile: filepath.Join(secretsDir, "age-test-key.txt")}))
+	logger := zerolog.Nop()
+	merged, err := MergeDataFiles([]*Input{secret}, nil, false, &logger)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", merged["secrets"].(map[string]any)["database"].(map[string]any)["password"])
+
+	redacted := RedactSecrets(merged, secret.SecretPaths)
+	assert.Equal(t, map[string]any{"host": "db.internal", "password": RedactedValue, "port": uint64(5432)},
+		redacted["secrets"].(map[string]any)["database"])
+	assert.Equal(t, []any{map[string]any{"name": "payments", "token": RedactedValue}},
+		redacted["secrets"].(map[string]any)["api_keys"])
+	// the original data is unchanged
+	assert.Equal(t, "hunter2", merged["secrets"].(map[string]any)["database"].(map[string]any)["password"])
+}
+
+func TestRedactSecrets(t *testing.T) {
+	data := map[string]any{"a": map[string]any{"b": "secret", "c": "plain"}, "list": []any{"x", "y"}}
+	paths := []spec.NormalizedPath{
+		spec.Normalized(spec.Name("a"), spec.Name("b")),
+		spec.Normalized(spec.Name("list"), spec.Index(1)),
+		spec.Normalized(spec.Name("missing")),
+	}
+	assert.Equal(t,
+		map[string]any{"a": map[string]any{"b": RedactedValue, "c": "plain"}, "list": []any{"x", RedactedValue}},
+		RedactSecrets(data, paths))
+	assert.Equal(t, data, RedactSecrets(data, nil))
+}
@@ -3,30 +3,96 @@ package data
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/theory/jsonpath"
 	"github.com/theory/jsonpath/spec"
 )
 
+// SetKind is the flag a value was set with, which decides how the value is parsed.
+type SetKind string
+
+// Set kinds, in the order they are applied.
+const (
+	SetKindJSON   SetKind = "set-json"   // value must be JSON
+	SetKindValue  SetKind = "set"        // value is parsed as JSON if possible, otherwise a string
+	SetKindString SetKind = "set-string" // value is always a string
+	SetKindFile   SetKind = "set-file"   // value is the path of a file whose contents are the string value
+	SetKindUnset  SetKind = "unset"      // the path is removed, there is no value
+)
+
+// SetArg is a single --set style flag value. Arg is "path=value", "path+=value" or "path[-]=value" to
+// append to an array, or just "path" for --unset.
+type SetArg struct {
+	Kind SetKind
+	Arg  string
+}
+
+// NewSetArgs creates SetArgs of one kind from flag values.
+func NewSetArgs(kind SetKind, args []string) []SetArg {
+	setArgs := make([]SetArg, 0, len(args))
+	for _, arg := range args {
+		setArgs = append(setArgs, SetArg{Kind: kind, Arg: arg})
+	}
+	return setArgs
+}
+
+// String returns the flag as it would be given on the command line.
+func (sa SetArg) String() string {
+	return "--" + string(sa.Kind) + " " + sa.Arg
+}
+
+// Parse returns the JSONPath and value of the set arg, and whether the value is appended to an array at
+// the path rather than replacing it. The value is nil for --unset.
+func (sa SetArg) Parse() (path string, value any, isAppend bool, err error) {
+	if sa.Kind == SetKindUnset {
+		return checkPathPrefix(sa.Arg), nil, false, nil
+	}
+	path, rawValue, found := cutSetString(sa.Arg)
+	if !found {
+		return "", nil, false, fmt.Errorf("no '=' found in set string: %s", sa.Arg)
+	}
+	path = strings.TrimSpace(path)
+	if trimmed, ok := strings.CutSuffix(path, "+"); ok {
+		path, isAppend = trimmed, true
+	} else if trimmed, ok = strings.CutSuffix(path, "[-]"); ok {
+		path, isAppend = trimmed, true
+	}
+	if strings.Contains(path, "[-]") {
+		return "", nil, false, fmt.Errorf("[-] is only supported at the end of a path: %s", sa.Arg)
+	}
+	path = checkPathPrefix(path)
+
+	switch sa.Kind {
+	case SetKindJSON:
+		if err = json.Unmarshal([]byte(rawValue), &value); err != nil {
+			return "", nil, false, fmt.Errorf("invalid JSON value: %w", err)
+		}
+	case SetKindString:
+		value = rawValue
+	case SetKindFile:
+		content, err := os.ReadFile(rawValue)
+		if err != nil {
+			return "", nil, false, fmt.Errorf("unable to read file: %w", err)
+		}
+		value = string(content)
+	default:
+		if err = json.Unmarshal([]byte(rawValue), &value); err != nil {
+			value = rawValue
+		}
+	}
+	return path, value, isAppend, nil
+}
+
 // SplitSetString parses a --set flag string in the format "path=value" and returns the JSONPath and value.
 // The value is automatically unmarshalled from JSON if possible, otherwise returned as a string.
 // Convenience feature: paths starting with '.' are auto-prefixed with '$'.
 func SplitSetString(s string) (path string, interfaceValue any, err error) {
-	var value string
-	switch strings.Count(s, "=") {
-	case 0:
+	path, value, found := cutSetString(s)
+	if !found {
 		return "", "", fmt.Errorf("no '=' found in set string: %s", s)
-	case 1:
-		parts := strings.SplitN(s, "=", 2)
-		path = parts[0]
-		value = parts[1]
-	default:
-		// may handle this differently in the future if it becomes an issue that
-		// people want to have '=' in their keys we can implement escaping or something
-		parts := strings.SplitN(s, "=", 2)
-		path = parts[0]
-		value = parts[1]
 	}
 
 	path = checkPathPrefix(path)
@@ -38,6 +104,33 @@ func SplitSetString(s string) (path string, interfaceValue any, err error) {
 	return path, interfaceValue, nil
 }
 
+// cutSetString splits a set string on the first '=' that is outside of brackets and quotes, so that
+// filter expressions like $.a[?@.b=="c"] are part of the path.
+func cutSetString(s string) (path, value string, found bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++ // skip the escaped character
+			} else if c == quote {
+				quote = 0
+			}
+		case depth > 0 && (c == '\'' || c == '"'):
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case c == '=' && depth <= 0:
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
 func checkPathPrefix(path string) string {
 	// Convenience: auto-prefix with $ if path starts with .
 	path = strings.TrimSpace(path)
@@ -134,6 +227,48 @@ func SetValueInData(data *any, segments []*spec.Segment, value any, setString st
 	return nil
 }
 
+// AppendValueInData appends a value to the array at the JSONPath segments, creating the array if the path
+// does not exist.
+func AppendValueInData(data *any, segments []*spec.Segment, value any, setString string) error {
+	existing := spec.Query(true, segments...).Select(*data, *data)
+	if len(existing) == 0 || existing[0] == nil {
+		return SetValueInData(data, segments, []any{value}, setString)
+	}
+	arr, ok := existing[0].([]any)
+	if !ok {
+		return fmt.Errorf("error appending --set value '%s': expected array but found %T", setString, existing[0])
+	}
+	return SetValueInData(data, segments, append(arr, value), setString)
+}
+
+// UnsetValueInData removes the values matched by the JSONPath from the data, a map key is deleted and an
+// array element is removed, shifting later elements down. Paths that match nothing are ignored.
+func UnsetValueInData(data map[string]any, path *jsonpath.Path) error {
+	located := path.SelectLocated(data)
+	// remove later array elements first so that the indexes of earlier ones stay valid
+	slices.SortFunc(located, func(a, b *spec.LocatedNode) int { return b.Path.Compare(a.Path) })
+	located = slices.CompactFunc(located, func(a, b *spec.LocatedNode) bool { return a.Path.Compare(b.Path) == 0 })
+	for _, node := range located {
+		location := node.Path
+		if len(location) == 0 {
+			return fmt.Errorf("unable to unset the root of the data")
+		}
+		parentLocation := location[:len(location)-1]
+		parent, _ := getAtPath(data, parentLocation)
+		switch sel := location[len(location)-1].(type) {
+		case spec.Name:
+			if m, ok := parent.(map[string]any); ok {
+				delete(m, string(sel))
+			}
+		case spec.Index:
+			if arr, ok := parent.([]any); ok {
+				setAtPath(data, parentLocation, slices.Delete(arr, int(sel), int(sel)+1))
+			}
+		}
+	}
+	return nil
+}
+
 func createNextContainer(selector spec.Selector) any {
 	if _, isIndex := selector.(spec.Index); isIndex {
 		return make([]any, 0)
@@ -0,0 +1,183 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySetArgs(t *testing.T) {
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	assert.NoError(t, os.WriteFile(certFile, []byte("-----BEGIN CERTIFICATE-----\n"), 0o644))
+
+	tests := []struct {
+		name        string
+		initial     map[string]any
+		setArgs     []SetArg
+		expected    map[string]any
+		expectError string
+	}{
+		{
+			name:     "set parses json",
+			setArgs:  []SetArg{{Kind: SetKindValue, Arg: ".port=8080"}, {Kind: SetKindValue, Arg: ".name=app"}},
+			expected: map[string]any{"port": float64(8080), "name": "app"},
+		},
+		{
+			name:     "set-string never parses json",
+			setArgs:  []SetArg{{Kind: SetKindString, Arg: ".version=1.10"}, {Kind: SetKindString, Arg: ".enabled=true"}},
+			expected: map[string]any{"version": "1.10", "enabled": "true"},
+		},
+		{
+			name:     "set-json",
+			setArgs:  []SetArg{{Kind: SetKindJSON, Arg: `.config={"replicas":2}`}},
+			expected: map[string]any{"config": map[string]any{"replicas": float64(2)}},
+		},
+		{
+			name:        "set-json with invalid json",
+			setArgs:     []SetArg{{Kind: SetKindJSON, Arg: ".config=nope"}},
+			expectError: "error parsing --set-json .config=nope: invalid JSON value",
+		},
+		{
+			name:     "set-file",
+			setArgs:  []SetArg{{Kind: SetKindFile, Arg: ".tls.cert=" + certFile}},
+			expected: map[string]any{"tls": map[string]any{"cert": "-----BEGIN CERTIFICATE-----\n"}},
+		},
+		{
+			name:        "set-file missing file",
+			setArgs:     []SetArg{{Kind: SetKindFile, Arg: ".tls.cert=./does-not-exist.pem"}},
+			expectError: "unable to read file",
+		},
+		{
+			name:    "append with [-] and +=",
+			initial: map[string]any{"items": []any{"a"}},
+			setArgs: []SetArg{
+				{Kind: SetKindValue, Arg: ".items[-]=b"},
+				{Kind: SetKindString, Arg: ".items+=3"},
+				{Kind: SetKindValue, Arg: ".new[-]=1"},
+			},
+			expected: map[string]any{"items": []any{"a", "b", "3"}, "new": []any{float64(1)}},
+		},
+		{
+			name:        "append to a non-array",
+			initial:     map[string]any{"items": "a"},
+			setArgs:     []SetArg{{Kind: SetKindValue, Arg: ".items+=b"}},
+			expectError: "expected array but found string",
+		},
+		{
+			name:        "[-] in the middle of a path",
+			setArgs:     []SetArg{{Kind: SetKindValue, Arg: ".items[-].name=b"}},
+			expectError: "[-] is only supported at the end of a path",
+		},
+		{
+			name:    "unset keys and array elements",
+			initial: map[string]any{"a": 1, "b": map[string]any{"c": 2, "d": 3}, "items": []any{"x", "y", "z"}},
+			setArgs: []SetArg{
+				{Kind: SetKindUnset, Arg: ".a"},
+				{Kind: SetKindUnset, Arg: ".b.c"},
+				{Kind: SetKindUnset, Arg: ".items[1]"},
+				{Kind: SetKindUnset, Arg: ".does.not.exist"},
+			},
+			expected: map[string]any{"b": map[string]any{"d": 3}, "items": []any{"x", "z"}},
+		},
+		{
+			name: "wildcard sets below every match",
+			initial: map[string]any{"services": []any{
+				map[string]any{"name": "a"},
+				map[string]any{"name": "b", "replicas": float64(1)},
+			}},
+			setArgs: []SetArg{{Kind: SetKindValue, Arg: "$.services[*].replicas=2"}},
+			expected: map[string]any{"services": []any{
+				map[string]any{"name": "a", "replicas": float64(2)},
+				map[string]any{"name": "b", "replicas": float64(2)},
+			}},
+		},
+		{
+			name: "filter sets and appends below matches only",
+			initial: map[string]any{"services": []any{
+				map[string]any{"tier": "web"},
+				map[string]any{"tier": "db"},
+			}},
+			setArgs: []SetArg{
+				{Kind: SetKindValue, Arg: `$.services[?@.tier=="web"].image=nginx`},
+				{Kind: SetKindJSON, Arg: `$.services[?@.tier=="db"].ports[-]={"port":5432}`},
+			},
+			expected: map[string]any{"services": []any{
+				map[string]any{"tier": "web", "image": "nginx"},
+				map[string]any{"tier": "db", "ports": []any{map[string]any{"port": float64(5432)}}},
+			}},
+		},
+		{
+			name:     "descendant path replaces existing matches",
+			initial:  map[string]any{"a": map[string]any{"debug": true}, "b": map[string]any{"c": map[string]any{"debug": true}}},
+			setArgs:  []SetArg{{Kind: SetKindValue, Arg: "$..debug=false"}},
+			expected: map[string]any{"a": map[string]any{"debug": false}, "b": map[string]any{"c": map[string]any{"debug": false}}},
+		},
+		{
+			name:     "overlapping selectors append once per node",
+			initial:  map[string]any{"items": []any{map[string]any{}, map[string]any{}}},
+			setArgs:  []SetArg{{Kind: SetKindValue, Arg: "$.items[0,1,0].tags+=x"}},
+			expected: map[string]any{"items": []any{map[string]any{"tags": []any{"x"}}, map[string]any{"tags": []any{"x"}}}},
+		},
+		{
+			name:     "wildcard with no matches",
+			initial:  map[string]any{"services": []any{}},
+			setArgs:  []SetArg{{Kind: SetKindValue, Arg: "$.services[*].replicas=2"}},
+			expected: map[string]any{"services": []any{}},
+		},
+		{
+			name:    "unset with wildcard and filter",
+			initial: map[string]any{"items": []any{float64(1), float64(5), float64(2), float64(7)}, "m": map[string]any{"a": 1, "b": 2}},
+			setArgs: []SetArg{
+				{Kind: SetKindUnset, Arg: "$.items[?@ > 3]"},
+				{Kind: SetKindUnset, Arg: "$.m.*"},
+			},
+			expected: map[string]any{"items": []any{float64(1), float64(2)}, "m": map[string]any{}},
+		},
+		{
+			name:        "unset root",
+			initial:     map[string]any{"a": 1},
+			setArgs:     []SetArg{{Kind: SetKindUnset, Arg: "$"}},
+			expectError: "unable to unset the root of the data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.initial
+			if data == nil {
+				data = make(map[string]any)
+			}
+			logger := zerolog.Nop()
+			err := applySetArgs(data, tt.setArgs, &logger)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, data)
+		})
+	}
+}
+
+func TestSplitSetString(t *testing.T) {
+	path, value, err := SplitSetString(`.services[?@.tier=="web"].image=nginx:1.2=3`)
+	assert.NoError(t, err)
+	assert.Equal(t, `$.services[?@.tier=="web"].image`, path)
+	assert.Equal(t, "nginx:1.2=3", value)
+
+	path, value, err = SplitSetString(`$["a=]\"b"]=1`)
+	assert.NoError(t, err)
+	assert.Equal(t, `$["a=]\"b"]`, path)
+	assert.EqualValues(t, 1, value)
+
+	_, _, err = SplitSetString(`.a[?@.b=="c"]`)
+	assert.ErrorContains(t, err, "no '=' found")
+}
+
+func TestSetArgString(t *testing.T) {
+	assert.Equal(t, "--set-string .a=1", SetArg{Kind: SetKindString, Arg: ".a=1"}.String())
+	assert.Equal(t, []SetArg{{Kind: SetKindUnset, Arg: ".a"}}, NewSetArgs(SetKindUnset, []string{".a"}))
+}
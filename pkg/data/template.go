@@ -0,0 +1,25 @@
+package data
+
+import (
+	"bytes"
+
+	"github.com/adam-huganir/yutc/pkg/templates"
+)
+
+// renderDataTemplate executes a data file as a go template, with the same functions available to
+// templates, against a copy of the data merged before it.
+func renderDataTemplate(name string, content []byte, merged map[string]any, info TemplateInfo) ([]byte, error) {
+	t, err := templates.InitTemplate(nil, info.Strict, info.AllowShell)
+	if err != nil {
+		return nil, err
+	}
+	t, err = t.New(name).Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, copyData(merged)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -53,6 +53,9 @@ func ParseSourceInputWithTempDir(arg, tempDir string) (*ParsedSourceInput, error
 	if sourceType == loader.SourceKindStdin && argParsed.Source.Value != "-" {
 		return nil, fmt.Errorf("stdin source requires src to be '-': %s", arg)
 	}
+	if sourceType == loader.SourceKindEnv && argParsed.Source.Value != "env" {
+		return nil, fmt.Errorf("env source requires src to be 'env': %s", arg)
+	}
 
 	entryOpts := []loader.FileEntryOption{loader.WithSource(sourceType)}
 	entryName := argParsed.Source.Value
@@ -72,6 +75,13 @@ func ParseSourceInputWithTempDir(arg, tempDir string) (*ParsedSourceInput, error
 		entryOpts = append(entryOpts, loader.WithGitSource(argParsed.Source.Value, ref, path, tempDir, recurseSubmodules))
 		entryName = loader.NormalizeGitSourceValue(argParsed.Source.Value)
 	}
+	if sourceType == loader.SourceKindEnv {
+		prefix, separator, err := parseEnvArgs(argParsed)
+		if err != nil {
+			return nil, err
+		}
+		entryOpts = append(entryOpts, loader.WithEnvSource(prefix, separator))
+	}
 
 	var auth *loader.AuthInfo
 	if argParsed.Auth != nil {
@@ -112,3 +122,23 @@ func parseGitSubmodulesArg(argParsed *lexer.Arg) (bool, error) {
 
 	return false, nil
 }
+
+func parseEnvArgs(argParsed *lexer.Arg) (prefix, separator string, err error) {
+	if argParsed == nil || argParsed.Type == nil {
+		return "", "", nil
+	}
+	for argName, argValue := range argParsed.Type.Args {
+		switch argName {
+		case "prefix":
+			prefix = argValue
+		case "separator":
+			if argValue == "" {
+				return "", "", fmt.Errorf("invalid value for 'separator' argument: must not be empty")
+			}
+			separator = argValue
+		default:
+			return "", "", fmt.Errorf("invalid argument %q for type=env(): only 'prefix' and 'separator' are allowed", argName)
+		}
+	}
+	return prefix, separator, nil
+}
@@ -16,6 +16,11 @@ type Arg struct {
 	Type     *TypeField
 	Ref      *RefField
 	Path     *PathField
+	Format   *FormatField
+	Include  *GlobsField
+	Exclude  *GlobsField
+	Out      *OutField
+	Mode     *ModeField
 }
 
 func (a *Arg) Map() map[string]FieldInterface {
@@ -27,6 +32,11 @@ func (a *Arg) Map() map[string]FieldInterface {
 		"type":     a.Type,
 		"ref":      a.Ref,
 		"path":     a.Path,
+		"format":   a.Format,
+		"include":  a.Include,
+		"exclude":  a.Exclude,
+		"out":      a.Out,
+		"mode":     a.Mode,
 	}
 }
 
@@ -88,6 +98,37 @@ type PathField struct {
 func (f *PathField) GetValue() string           { return f.Value }
 func (f *PathField) GetArgs() map[string]string { return nil }
 
+type FormatField struct {
+	Value string
+}
+
+func (f *FormatField) GetValue() string           { return f.Value }
+func (f *FormatField) GetArgs() map[string]string { return nil }
+
+// OutField holds the output path of a template argument.
+type OutField struct {
+	Value string
+}
+
+func (f *OutField) GetValue() string           { return f.Value }
+func (f *OutField) GetArgs() map[string]string { return nil }
+
+// ModeField holds the octal file mode of the outputs of a template argument.
+type ModeField struct {
+	Value string
+}
+
+func (f *ModeField) GetValue() string           { return f.Value }
+func (f *ModeField) GetArgs() map[string]string { return nil }
+
+// GlobsField holds the globs of a key that can be repeated, ex: exclude=*.md,exclude=.github.
+type GlobsField struct {
+	Values []string
+}
+
+func (f *GlobsField) GetValue() string           { return strings.Join(f.Values, ",") }
+func (f *GlobsField) GetArgs() map[string]string { return nil }
+
 type KeyValidator func(key string) error
 
 type ValueValidator func(key string, value string) error
@@ -115,6 +156,11 @@ func DefaultKeyValidator(key string) error {
 		"type":     true,
 		"ref":      true,
 		"path":     true,
+		"format":   true,
+		"include":  true,
+		"exclude":  true,
+		"out":      true,
+		"mode":     true,
 	}
 	if !allowedKeys[key] {
 		keys := slices.Sorted(maps.Keys(allowedKeys))
@@ -349,6 +395,22 @@ func (p *Parser) parseField(arg *Arg) error {
 		arg.Path = &PathField{
 			Value: fieldValue,
 		}
+	case "format":
+		arg.Format = &FormatField{
+			Value: fieldValue,
+		}
+	case "include":
+		arg.Include = appendGlob(arg.Include, fieldValue)
+	case "exclude":
+		arg.Exclude = appendGlob(arg.Exclude, fieldValue)
+	case "out":
+		arg.Out = &OutField{
+			Value: fieldValue,
+		}
+	case "mode":
+		arg.Mode = &ModeField{
+			Value: fieldValue,
+		}
 	default:
 		// Unknown key - only error if validation is enabled
 		if p.validation != nil {
@@ -364,6 +426,15 @@ func (p *Parser) parseField(arg *Arg) error {
 	return nil
 }
 
+// appendGlob adds a value of a repeatable glob key to its field.
+func appendGlob(field *GlobsField, value string) *GlobsField {
+	if field == nil {
+		field = &GlobsField{}
+	}
+	field.Values = append(field.Values, value)
+	return field
+}
+
 func (p *Parser) parseArgs(args map[string]string) error {
 	for p.current().Type != ParenExitCall && p.current().Type != EOF {
 		keyToken, err := p.expect(KEY)
@@ -127,6 +127,54 @@ func TestParser_Parse(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:  "format field",
+			input: "src=-,format=toml",
+			want: &Arg{
+				Source: &SourceField{
+					Value: "-",
+				},
+				Format: &FormatField{
+					Value: "toml",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "repeated include and exclude globs",
+			input: "src=./tmpl,include=**/*.tmpl,exclude=*.md,exclude=.github",
+			want: &Arg{
+				Source: &SourceField{
+					Value: "./tmpl",
+				},
+				Include: &GlobsField{
+					Values: []string{"**/*.tmpl"},
+				},
+				Exclude: &GlobsField{
+					Values: []string{"*.md", ".github"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "jsonpath, out and mode",
+			input: "src=./svc.tmpl,jsonpath=.services.web,out=web.yaml,mode=0755",
+			want: &Arg{
+				Source: &SourceField{
+					Value: "./svc.tmpl",
+				},
+				JSONPath: &JSONPathField{
+					Value: ".services.web",
+				},
+				Out: &OutField{
+					Value: "web.yaml",
+				},
+				Mode: &ModeField{
+					Value: "0755",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:  "type field",
 			input: "src=./repo,type=git(submodules=recurse)",
@@ -248,12 +296,12 @@ func TestParser_Parse_ValidationErrors(t *testing.T) {
 		{
 			name:    "invalid key",
 			input:   "invalid=value",
-			wantErr: "invalid key 'invalid': allowed keys are auth, jsonpath, kind, path, ref, src, type",
+			wantErr: "invalid key 'invalid': allowed keys are auth, exclude, format, include, jsonpath, kind, mode, out, path, ref, src, type",
 		},
 		{
 			name:    "invalid key with valid keys",
 			input:   "jsonpath=.Secrets,invalid=value",
-			wantErr: "invalid key 'invalid': allowed keys are auth, jsonpath, kind, path, ref, src, type",
+			wantErr: "invalid key 'invalid': allowed keys are auth, exclude, format, include, jsonpath, kind, mode, out, path, ref, src, type",
 		},
 	}
 	for _, tt := range tests {
@@ -0,0 +1,110 @@
+// Package lint describes problems found in templates without rendering them and reports them as text or SARIF.
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Level is the severity of a finding, named as in SARIF.
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNote    Level = "note"
+)
+
+// Rule is a kind of problem reported by lint.
+type Rule struct {
+	ID          string
+	Level       Level
+	Description string
+}
+
+var (
+	RuleParseError = Rule{
+		ID: "parse-error", Level: LevelError,
+		Description: "The template can't be parsed",
+	}
+	RuleUndefinedTemplate = Rule{
+		ID: "undefined-template", Level: LevelError,
+		Description: "include or template calls a template that is never defined",
+	}
+	RuleUnusedDefine = Rule{
+		ID: "unused-define", Level: LevelWarning,
+		Description: "A define block is never used by include or template",
+	}
+	RuleMissingData = Rule{
+		ID: "missing-data", Level: LevelWarning,
+		Description: "A data key is used that is neither in the merged data nor declared by a schema",
+	}
+	RuleShellNotAllowed = Rule{
+		ID: "shell-not-allowed", Level: LevelError,
+		Description: "The shell function is used without --allow-shell",
+	}
+	RuleTrimJoinsText = Rule{
+		ID: "trim-joins-text", Level: LevelWarning,
+		Description: "{{- or -}} removes the whitespace between an action's output and the text next to it",
+	}
+	RuleTrailingWhitespace = Rule{
+		ID: "trailing-whitespace", Level: LevelNote,
+		Description: "A line of the template ends with whitespace",
+	}
+)
+
+// Rules lists every rule, in the order they are documented.
+var Rules = []Rule{
+	RuleParseError,
+	RuleUndefinedTemplate,
+	RuleUnusedDefine,
+	RuleMissingData,
+	RuleShellNotAllowed,
+	RuleTrimJoinsText,
+	RuleTrailingWhitespace,
+}
+
+// Finding is a problem found in a template.
+type Finding struct {
+	Rule    Rule
+	Path    string // path of the template file
+	Line    int    // from 1, 0 if unknown
+	Column  int    // from 1, 0 if unknown
+	Message string
+}
+
+// Sort orders findings by location and rule.
+func Sort(findings []Finding) {
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			cmp.Compare(a.Line, b.Line),
+			cmp.Compare(a.Column, b.Column),
+			cmp.Compare(a.Rule.ID, b.Rule.ID),
+		)
+	})
+}
+
+// HasErrors reports whether any finding is at the error level.
+func HasErrors(findings []Finding) bool {
+	return slices.ContainsFunc(findings, func(f Finding) bool { return f.Rule.Level == LevelError })
+}
+
+// WriteText writes one line per finding, ex: `a.tmpl:3:7: warning: .image is missing (missing-data)`.
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		location := f.Path
+		if f.Line > 0 {
+			location += fmt.Sprintf(":%d", f.Line)
+			if f.Column > 0 {
+				location += fmt.Sprintf(":%d", f.Column)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", location, f.Rule.Level, f.Message, f.Rule.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
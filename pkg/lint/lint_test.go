@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFindings() []Finding {
+	findings := []Finding{
+		{Rule: RuleTrailingWhitespace, Path: "b.tmpl", Line: 2, Column: 5, Message: "trailing whitespace"},
+		{Rule: RuleUndefinedTemplate, Path: "a.tmpl", Line: 3, Column: 7, Message: `template "x" is not defined`},
+		{Rule: RuleParseError, Path: "a.tmpl", Line: 1, Message: `function "nope" not defined`},
+		{Rule: RuleMissingData, Path: "/abs/c.tmpl", Message: ".x is not in the data or a schema"},
+	}
+	Sort(findings)
+	return findings
+}
+
+func TestWriteText(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, WriteText(&b, testFindings()))
+	assert.Equal(t, "/abs/c.tmpl: warning: .x is not in the data or a schema (missing-data)\n"+
+		"a.tmpl:1: error: function \"nope\" not defined (parse-error)\n"+
+		"a.tmpl:3:7: error: template \"x\" is not defined (undefined-template)\n"+
+		"b.tmpl:2:5: note: trailing whitespace (trailing-whitespace)\n", b.String())
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.True(t, HasErrors(testFindings()))
+	assert.False(t, HasErrors([]Finding{{Rule: RuleUnusedDefine}, {Rule: RuleTrailingWhitespace}}))
+	assert.False(t, HasErrors(nil))
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, WriteSARIF(&b, testFindings(), "1.2.3"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(b.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal(t, "yutc", driver.Name)
+	assert.Equal(t, "1.2.3", driver.Version)
+	assert.Len(t, driver.Rules, len(Rules))
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 4)
+	assert.Equal(t, "file:///abs/c.tmpl", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Nil(t, results[0].Locations[0].PhysicalLocation.Region)
+	assert.Equal(t, sarifResult{
+		RuleID:    RuleUndefinedTemplate.ID,
+		RuleIndex: 1,
+		Level:     LevelError,
+		Message:   sarifMessage{Text: `template "x" is not defined`},
+		Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "a.tmpl"},
+			Region:           &sarifRegion{StartLine: 3, StartColumn: 7},
+		}}},
+	}, results[2])
+	assert.Equal(t, driver.Rules[results[3].RuleIndex].ID, results[3].RuleID)
+}
+
+func TestArtifactURI(t *testing.T) {
+	assert.Equal(t, "templates/a.tmpl", artifactURI("./templates/a.tmpl"))
+	assert.Equal(t, "a.tmpl", artifactURI("a.tmpl"))
+	assert.Equal(t, "file:///tmp/a.tmpl", artifactURI("/tmp/a.tmpl"))
+}
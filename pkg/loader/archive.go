@@ -16,6 +16,7 @@ import (
 type FilePathMap struct {
 	FilePath string
 	Data     []byte
+	Mode     os.FileMode // permissions recorded in the archive, 0 if none
 }
 
 // IsArchive checks if a file path has an archive extension (.tgz, .tar.gz, .tar, .zip, .gz).
@@ -110,6 +111,7 @@ func ReadTarFromReader(name string, r io.Reader) ([]FilePathMap, error) {
 		files = append(files, FilePathMap{
 			FilePath: header.Name,
 			Data:     data,
+			Mode:     header.FileInfo().Mode().Perm(),
 		})
 	}
 	return files, nil
@@ -144,6 +146,7 @@ func ReadZip(filePath string) ([]FilePathMap, error) {
 		files = append(files, FilePathMap{
 			FilePath: f.Name,
 			Data:     data,
+			Mode:     f.Mode().Perm(),
 		})
 	}
 	return files, nil
@@ -177,6 +180,7 @@ func ReadZipFromBytes(data []byte) ([]FilePathMap, error) {
 		files = append(files, FilePathMap{
 			FilePath: f.Name,
 			Data:     data,
+			Mode:     f.Mode().Perm(),
 		})
 	}
 	return files, nil
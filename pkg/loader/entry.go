@@ -29,6 +29,7 @@ const (
 	SourceKindStdin  SourceKind = "stdin"
 	SourceKindStdout SourceKind = "stdout"
 	SourceKindGit    SourceKind = "git"
+	SourceKindEnv    SourceKind = "env"
 )
 
 func (sk SourceKind) String() string {
@@ -37,10 +38,11 @@ func (sk SourceKind) String() string {
 
 // FileContent holds the raw bytes and metadata for a loaded file.
 type FileContent struct {
-	Filename string // name of file, either from path or url, or '-' for stdin
-	Mimetype string // mimetype if known
-	Data     []byte // contents of file gathered during load/download
-	Read     bool   // whether the file has been read into memory
+	Filename string      // name of file, either from path or url, or '-' for stdin
+	Mimetype string      // mimetype if known
+	Mode     os.FileMode // permissions of the file if known, 0 otherwise
+	Data     []byte      // contents of file gathered during load/download
+	Read     bool        // whether the file has been read into memory
 }
 
 // NewFileContent creates a FileContent with a pre-allocated buffer.
@@ -81,11 +83,12 @@ func ParseAuthString(authStr string) AuthInfo {
 // It handles source detection, reading bytes from file/url/stdin, and path normalization.
 type FileEntry struct {
 	Name    string     // File path, URL, or "-" for stdin
-	Source  SourceKind // Source of data: "file", "url", "stdin", or "stdout"
+	Source  SourceKind // Source of data: "file", "url", "stdin", "stdout", "git", or "env"
 	Content *FileContent
 	Auth    AuthInfo
 	Remote  RemoteInfo
 	Git     *GitInfo
+	Env     *EnvInfo
 	logger  *zerolog.Logger
 
 	isDir  *bool // Cached IsDir result
@@ -226,6 +229,15 @@ func (f *FileEntry) NormalizePath() {
 	f.Name = NormalizeFilepath(f.Name)
 }
 
+// ResolveRelativeTo makes the path of a file source that is relative to the working directory relative to dir
+// instead. Other sources and absolute paths are left as is.
+func (f *FileEntry) ResolveRelativeTo(dir string) {
+	if dir == "" || f.Source != SourceKindFile || f.Name == "-" || filepath.IsAbs(filepath.FromSlash(f.Name)) {
+		return
+	}
+	f.Name = NormalizeFilepath(filepath.Join(dir, f.Name))
+}
+
 func (f *FileEntry) ioPath() (string, error) {
 	if f.Source == SourceKindGit {
 		if err := f.EnsureGitCheckout(); err != nil {
@@ -275,6 +287,11 @@ func (f *FileEntry) Load() (err error) {
 		if err != nil {
 			return err
 		}
+	case SourceKindEnv:
+		err = f.ReadEnv()
+		if err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unknown source %s", f.Source)
 	}
@@ -309,6 +326,9 @@ func (f *FileEntry) ReadFile() (err error) {
 	if err != nil {
 		return err
 	}
+	if info, err := os.Stat(name); err == nil {
+		f.Content.Mode = info.Mode().Perm()
+	}
 	f.Content.Read = true
 	f.Content.Filename = filepath.Base(name)
 	mimetype, err := getMimetype(f.Content.Data)
@@ -414,7 +434,7 @@ func (f *FileEntry) IsDir() (bool, error) {
 	if f.isDir != nil {
 		return *f.isDir, nil
 	}
-	if f.Source == SourceKindURL {
+	if f.Source == SourceKindURL || f.Source == SourceKindStdin || f.Source == SourceKindEnv {
 		res := false
 		f.isDir = &res
 		return res, nil
@@ -434,7 +454,7 @@ func (f *FileEntry) IsFile() (bool, error) {
 	if f.isFile != nil {
 		return *f.isFile, nil
 	}
-	if f.Source == SourceKindStdin {
+	if f.Source == SourceKindStdin || f.Source == SourceKindEnv {
 		res := true
 		f.isFile = &res
 		return res, nil
@@ -451,7 +471,7 @@ func (f *FileEntry) IsFile() (bool, error) {
 }
 
 func (f *FileEntry) IsArchive() (bool, error) {
-	if f.Source == SourceKindStdin {
+	if f.Source == SourceKindStdin || f.Source == SourceKindEnv {
 		return false, nil
 	}
 	name, err := f.ioPath()
@@ -492,6 +512,7 @@ func (f *FileEntry) IsContainer() (bool, error) {
 	return isArchive || isDir, nil
 }
 
+// IsText reports whether the content of the file is text, from its mimetype, detected from the content if unknown.
 func (f *FileEntry) IsText() (bool, error) {
 	if f.Content.Mimetype == "" {
 		if err := AssertRead(f); err != nil {
@@ -501,8 +522,26 @@ func (f *FileEntry) IsText() (bool, error) {
 		if !utf8.Valid(f.Content.Data) {
 			return false, nil
 		}
+		mimetype, err := getMimetype(f.Content.Data)
+		if err != nil {
+			return false, err
+		}
+		f.Content.Mimetype = mimetype
+	}
+	return isTextMimetype(f.Content.Mimetype), nil
+}
+
+// isTextMimetype reports whether mimetype is a text/* type or a structured text format such as JSON or YAML.
+func isTextMimetype(mimetype string) bool {
+	if strings.HasPrefix(mimetype, "text/") {
+		return true
+	}
+	switch mimetype {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml", "application/toml",
+		"application/javascript", "application/x-sh":
+		return true
 	}
-	return strings.Contains(f.Content.Mimetype, "text"), nil
+	return strings.HasSuffix(mimetype, "+json") || strings.HasSuffix(mimetype, "+xml") || strings.HasSuffix(mimetype, "+yaml")
 }
 
 func GetURL(u *url.URL, basicAuth, bearerToken string) (data *http.Response, err error) {
@@ -0,0 +1,33 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileEntry_IsText(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		mimetype string
+		want     bool
+	}{
+		{name: "template", data: []byte("name: {{ .name }}\n"), want: true},
+		{name: "empty", data: []byte{}, want: true},
+		{name: "png", data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), want: false},
+		{name: "invalid utf-8", data: []byte{0xff, 0xfe, 0x00, 0x01}, want: false},
+		{name: "json mimetype", data: []byte("{}"), mimetype: "application/json", want: true},
+		{name: "binary mimetype", data: []byte("abc"), mimetype: "application/octet-stream", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFileEntry("file", WithSource(SourceKindFile), WithContentBytes(tt.data))
+			f.Content.Mimetype = tt.mimetype
+			isText, err := f.IsText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, isText)
+		})
+	}
+}
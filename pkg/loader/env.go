@@ -0,0 +1,101 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultEnvSeparator separates nested keys in environment variable names.
+const DefaultEnvSeparator = "__"
+
+// EnvInfo configures how environment variables are converted to data.
+type EnvInfo struct {
+	Prefix    string // Only variables starting with Prefix are included, and the prefix is removed from keys
+	Separator string // Separator between nested keys, defaults to DefaultEnvSeparator
+}
+
+// WithEnvSource configures the entry to read data from environment variables.
+func WithEnvSource(prefix, separator string) FileEntryOption {
+	return func(fe *FileEntry) {
+		if separator == "" {
+			separator = DefaultEnvSeparator
+		}
+		fe.Env = &EnvInfo{Prefix: prefix, Separator: separator}
+		fe.Source = SourceKindEnv
+	}
+}
+
+// ReadEnv converts the process environment into a JSON document. Keys are lowercased and split on the
+// separator into nested objects, e.g. APP_DB__HOST=x with prefix APP_ becomes {"db": {"host": "x"}}.
+// Values are decoded as JSON where possible, the same as --set values, and are otherwise strings.
+// A prefix is required, as the whole environment would hold unrelated and conflicting names.
+func (f *FileEntry) ReadEnv() error {
+	info := f.Env
+	if info == nil || info.Prefix == "" {
+		return fmt.Errorf("env source requires a prefix, ex: src=env,type=env(prefix=APP_)")
+	}
+	envData, err := EnvToData(os.Environ(), info.Prefix, info.Separator)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(envData)
+	if err != nil {
+		return fmt.Errorf("unable to encode environment data: %w", err)
+	}
+	f.Content.Filename = f.Name
+	f.Content.Mimetype = "application/json"
+	f.Content.Data = b
+	f.Content.Read = true
+	return nil
+}
+
+// EnvToData converts "KEY=value" pairs into nested data, see ReadEnv.
+func EnvToData(environ []string, prefix, separator string) (map[string]any, error) {
+	if separator == "" {
+		separator = DefaultEnvSeparator
+	}
+	// sort so that conflicts are reported consistently
+	environ = slices.Sorted(slices.Values(environ))
+
+	envData := make(map[string]any)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(name, prefix)), separator)
+		if slices.Contains(keys, "") {
+			return nil, fmt.Errorf("environment variable %s has an empty key segment", name)
+		}
+
+		var typed any
+		if err := json.Unmarshal([]byte(value), &typed); err != nil {
+			typed = value
+		}
+
+		current := envData
+		for i, key := range keys {
+			if i == len(keys)-1 {
+				if _, exists := current[key]; exists {
+					return nil, fmt.Errorf("environment variable %s conflicts with another variable setting %s", name, strings.Join(keys[:i+1], "."))
+				}
+				current[key] = typed
+				break
+			}
+			next, exists := current[key]
+			if !exists {
+				next = make(map[string]any)
+				current[key] = next
+			}
+			nextMap, isMap := next.(map[string]any)
+			if !isMap {
+				return nil, fmt.Errorf("environment variable %s conflicts with another variable setting %s", name, strings.Join(keys[:i+1], "."))
+			}
+			current = nextMap
+		}
+	}
+	return envData, nil
+}
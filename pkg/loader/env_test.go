@@ -0,0 +1,98 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvToData(t *testing.T) {
+	tests := []struct {
+		name        string
+		environ     []string
+		prefix      string
+		separator   string
+		expected    map[string]any
+		expectError string
+	}{
+		{
+			name:      "prefix and nesting",
+			environ:   []string{"APP_DB__HOST=db.local", "APP_DB__PORT=5432", "APP_DEBUG=true", "OTHER=ignored"},
+			prefix:    "APP_",
+			separator: "__",
+			expected: map[string]any{
+				"db":    map[string]any{"host": "db.local", "port": float64(5432)},
+				"debug": true,
+			},
+		},
+		{
+			name:      "json values",
+			environ:   []string{"APP_TAGS=[\"a\",\"b\"]", "APP_EMPTY=", "APP_OBJ={\"k\":\"v\"}"},
+			prefix:    "APP_",
+			separator: "__",
+			expected: map[string]any{
+				"tags":  []any{"a", "b"},
+				"empty": "",
+				"obj":   map[string]any{"k": "v"},
+			},
+		},
+		{
+			name:     "default separator",
+			environ:  []string{"X__Y=1", "Z=two=2"},
+			expected: map[string]any{"x": map[string]any{"y": float64(1)}, "z": "two=2"},
+		},
+		{
+			name:      "custom separator",
+			environ:   []string{"APP_DB_HOST=x"},
+			prefix:    "APP_",
+			separator: "_",
+			expected:  map[string]any{"db": map[string]any{"host": "x"}},
+		},
+		{
+			name:        "scalar and object conflict",
+			environ:     []string{"APP_DB__HOST=x", "APP_DB=y"},
+			prefix:      "APP_",
+			expectError: "environment variable APP_DB__HOST conflicts with another variable setting db",
+		},
+		{
+			name:        "empty segment",
+			environ:     []string{"APP_DB____HOST=x"},
+			prefix:      "APP_",
+			expectError: "environment variable APP_DB____HOST has an empty key segment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := EnvToData(tt.environ, tt.prefix, tt.separator)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestFileEntry_ReadEnv(t *testing.T) {
+	t.Setenv("YUTC_TEST_SERVER__PORT", "8080")
+	fe := NewFileEntry("env", WithEnvSource("YUTC_TEST_", ""))
+	assert.Equal(t, SourceKindEnv, fe.Source)
+	assert.Equal(t, "__", fe.Env.Separator)
+
+	isDir, err := fe.IsDir()
+	assert.NoError(t, err)
+	assert.False(t, isDir)
+
+	assert.NoError(t, fe.Load())
+	assert.Equal(t, "application/json", fe.Content.Mimetype)
+	assert.JSONEq(t, `{"server": {"port": 8080}}`, string(fe.Content.Data))
+}
+
+func TestFileEntry_ReadEnvRequiresPrefix(t *testing.T) {
+	// names that only differ in case would conflict once lowercased
+	t.Setenv("YUTC_TEST_A", "1")
+	t.Setenv("yutc_test_a", "2")
+	fe := NewFileEntry("env", WithEnvSource("", ""))
+	assert.EqualError(t, fe.Load(), "env source requires a prefix, ex: src=env,type=env(prefix=APP_)")
+}
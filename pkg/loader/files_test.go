@@ -90,6 +90,16 @@ func TestCheckIfDir(t *testing.T) {
 	assert.Equal(t, false, isDir)
 	_, err = IsDir("../../testFiles/NotAFile")
 	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// entries that never live on the filesystem are not directories
+	for _, fe := range []*FileEntry{
+		NewFileEntry("-", WithSource(SourceKindStdin)),
+		NewFileEntry("https://example.com/data", WithSource(SourceKindURL)),
+	} {
+		isDir, err = fe.IsDir()
+		assert.NoError(t, err)
+		assert.False(t, isDir, fe.Name)
+	}
 }
 
 func TestCheckIsFile(t *testing.T) {
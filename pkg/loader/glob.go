@@ -0,0 +1,66 @@
+package loader
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MatchGlob reports whether the slash separated path rel matches pattern as in .gitignore: a pattern without a
+// slash matches a file or directory name at any depth, others match from the root, ** matches any number of
+// directories, and a pattern matching a directory matches everything in it.
+func MatchGlob(pattern, rel string) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	patternSegments := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	segments := strings.Split(strings.TrimPrefix(path.Clean(rel), "/"), "/")
+	for i := len(segments); i > 0; i-- {
+		if matchSegments(patternSegments, segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAnyGlob reports whether rel matches any of patterns with MatchGlob.
+func MatchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if MatchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGlob returns an error if pattern is malformed.
+func ValidateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
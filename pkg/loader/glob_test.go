@@ -0,0 +1,40 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		rel     string
+		want    bool
+	}{
+		{"*.png", "logo.png", true},
+		{"*.png", "assets/img/logo.png", true},
+		{"*.png", "logo.png.tmpl", false},
+		{"assets", "assets/img/logo.png", true},
+		{"assets/", "assets/logo.png", true},
+		{"img/*.png", "assets/img/logo.png", false},
+		{"assets/img/*.png", "assets/img/logo.png", true},
+		{"/assets/*", "assets/logo.png", true},
+		{"**/img/*.png", "assets/img/logo.png", true},
+		{"**/img/*.png", "img/logo.png", true},
+		{"assets/**/*.png", "assets/a/b/logo.png", true},
+		{"assets/**/*.png", "other/a/logo.png", false},
+		{"**", "anything/at/all", true},
+		{"*.md", "docs", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.want, MatchGlob(tt.pattern, tt.rel))
+		})
+	}
+}
+
+func TestValidateGlob(t *testing.T) {
+	assert.NoError(t, ValidateGlob("**/*.png"))
+	assert.ErrorContains(t, ValidateGlob("assets/[a"), `invalid glob "assets/[a"`)
+}
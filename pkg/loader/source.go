@@ -103,6 +103,8 @@ func ParseSourceKind(value string) (SourceKind, error) {
 		return SourceKindStdout, nil
 	case string(SourceKindGit):
 		return SourceKindGit, nil
+	case string(SourceKindEnv):
+		return SourceKindEnv, nil
 	case "":
 		return "", fmt.Errorf("source kind is empty")
 	default:
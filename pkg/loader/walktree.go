@@ -84,6 +84,7 @@ func GetEntries(root *FileEntry, logger *zerolog.Logger) (entries []*FileEntry,
 				WithIsFile(true),
 				WithIsDir(false),
 			)
+			entry.Content.Mode = f.Mode
 			entries = append(entries, entry)
 		}
 		return entries, nil
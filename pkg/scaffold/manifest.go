@@ -0,0 +1,89 @@
+// Package scaffold reads the manifest of project templates rendered with `yutc new`.
+package scaffold
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"runtime"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/goccy/go-yaml"
+)
+
+// ManifestName is the name of the manifest file at the root of a project template.
+const ManifestName = "yutc.yaml"
+
+// Manifest describes how a project template is rendered.
+type Manifest struct {
+	// Schema is the JSON Schema of the project's values, inline or as a path relative to the manifest.
+	// Its required properties are the questions asked, with their defaults.
+	Schema any `yaml:"schema"`
+	// Exclude lists glob patterns of files and directories that are not rendered into the project.
+	Exclude []string `yaml:"exclude"`
+	Hooks   Hooks    `yaml:"hooks"`
+}
+
+// Hooks are shell commands run in the new project directory.
+type Hooks struct {
+	Post []string `yaml:"post"` // run after the project is rendered
+}
+
+// ParseManifest decodes the content of a manifest file.
+func ParseManifest(content []byte) (*Manifest, error) {
+	m := &Manifest{}
+	if err := yaml.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", ManifestName, err)
+	}
+	switch m.Schema.(type) {
+	case nil, string, map[string]any:
+	default:
+		return nil, fmt.Errorf("invalid schema in %s: must be an object or a path", ManifestName)
+	}
+	for _, pattern := range m.Exclude {
+		if err := loader.ValidateGlob(pattern); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern in %s: %w", ManifestName, err)
+		}
+	}
+	return m, nil
+}
+
+// SchemaPath returns the path of the schema file relative to the manifest, or "" if the schema is inline.
+func (m *Manifest) SchemaPath() string {
+	p, ok := m.Schema.(string)
+	if !ok || p == "" {
+		return ""
+	}
+	return path.Clean(p)
+}
+
+// Excluded reports whether the file at the slash separated path rel, relative to the project template,
+// is left out of the project. The manifest and schema file are always excluded, and the exclude patterns
+// match as loader.MatchGlob does for --template-glob and .yutcignore.
+func (m *Manifest) Excluded(rel string) bool {
+	rel = path.Clean(rel)
+	if rel == ManifestName || (m.SchemaPath() != "" && rel == m.SchemaPath()) {
+		return true
+	}
+	return loader.MatchAnyGlob(m.Exclude, rel)
+}
+
+// RunHooks runs each command in dir, in order, stopping at the first one that fails.
+func RunHooks(commands []string, dir string, stdout, stderr io.Writer) error {
+	for _, command := range commands {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("powershell", "-Command", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		cmd.Dir = dir
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %q failed: %w", command, err)
+		}
+	}
+	return nil
+}
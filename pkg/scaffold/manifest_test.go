@@ -0,0 +1,78 @@
+package scaffold
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte("schema:\n  type: object\nexclude: [hooks]\nhooks:\n  post: [git init]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"type": "object"}, m.Schema)
+	assert.Equal(t, "", m.SchemaPath())
+	assert.Equal(t, []string{"hooks"}, m.Exclude)
+	assert.Equal(t, []string{"git init"}, m.Hooks.Post)
+
+	m, err = ParseManifest([]byte("schema: ./questions.json\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "questions.json", m.SchemaPath())
+
+	_, err = ParseManifest([]byte("schema: [1]\n"))
+	assert.ErrorContains(t, err, "must be an object or a path")
+
+	_, err = ParseManifest([]byte("exclude: ['[']\n"))
+	assert.ErrorContains(t, err, "invalid exclude pattern")
+}
+
+func TestManifestExcluded(t *testing.T) {
+	m := &Manifest{Schema: "schema/questions.json", Exclude: []string{"hooks/", "*.bak", "docs/*.md", "/build", "**/gen/*.go"}}
+	tests := []struct {
+		path     string
+		excluded bool
+	}{
+		{path: ManifestName, excluded: true},
+		{path: "schema/questions.json", excluded: true},
+		{path: "hooks/post.sh", excluded: true},
+		{path: "src/hooks/post.sh", excluded: true},
+		{path: "build/out.txt", excluded: true},
+		{path: "src/build/out.txt", excluded: false},
+		{path: "notes.bak", excluded: true},
+		{path: "src/notes.bak", excluded: true},
+		{path: "docs/index.md", excluded: true},
+		{path: "docs/img/logo.png", excluded: false},
+		{path: "src/docs/index.md", excluded: false},
+		{path: "src/main.go", excluded: false},
+		{path: "gen/types.go", excluded: true},
+		{path: "src/api/gen/types.go", excluded: true},
+		{path: "src/api/gen/types.txt", excluded: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.excluded, m.Excluded(tt.path))
+		})
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with powershell on windows")
+	}
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	err := RunHooks([]string{"echo created > marker", "cat marker"}, dir, &stdout, &stdout)
+	require.NoError(t, err)
+	assert.Equal(t, "created\n", stdout.String())
+	_, err = os.Stat(filepath.Join(dir, "marker"))
+	assert.NoError(t, err)
+
+	err = RunHooks([]string{"exit 3", "touch never"}, dir, &stdout, &stdout)
+	assert.ErrorContains(t, err, `hook "exit 3" failed`)
+	_, err = os.Stat(filepath.Join(dir, "never"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// ItemSegment is the path segment that stands for any element of an array or value of an object.
+const ItemSegment = "[]"
+
+// schemaAt is a schema visited for a path, to stop recursive schemas.
+type schemaAt struct {
+	schema *jsonschema.Schema
+	depth  int
+}
+
+// Declares reports whether the schema describes the value at path, a list of object keys and ItemSegment
+// from the root of the validated data. A value is described by properties, matching pattern properties,
+// additionalProperties and items, following $ref, allOf, anyOf, oneOf and if/then/else. A schema that sets
+// none of these, nor a type, describes anything below it.
+func (c *Compiled) Declares(path []string) bool {
+	return declares(c.schema, path, make(map[schemaAt]bool))
+}
+
+func declares(s *jsonschema.Schema, path []string, visiting map[schemaAt]bool) bool {
+	at := schemaAt{schema: s, depth: len(path)}
+	if s == nil || visiting[at] {
+		return false
+	}
+	if len(path) == 0 || unconstrained(s) {
+		return true
+	}
+	visiting[at] = true
+	defer delete(visiting, at)
+
+	for _, sub := range children(s, path[0]) {
+		if declares(sub, path[1:], visiting) {
+			return true
+		}
+	}
+	for _, sub := range related(s) {
+		if declares(sub, path, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+// unconstrained reports whether s says nothing about the structure of a value, as with {} or true.
+func unconstrained(s *jsonschema.Schema) bool {
+	return s.Types == nil && s.Properties == nil && s.PatternProperties == nil && s.AdditionalProperties == nil &&
+		s.Items == nil && s.Items2020 == nil && s.PrefixItems == nil && s.AdditionalItems == nil &&
+		s.Enum == nil && s.Const == nil && len(related(s)) == 0
+}
+
+// children returns the schemas of the value with the key segment, ItemSegment for any value.
+func children(s *jsonschema.Schema, segment string) []*jsonschema.Schema {
+	var out []*jsonschema.Schema
+	if segment == ItemSegment {
+		switch items := s.Items.(type) {
+		case *jsonschema.Schema:
+			out = append(out, items)
+		case []*jsonschema.Schema:
+			out = append(out, items...)
+		}
+		if additional, ok := s.AdditionalItems.(*jsonschema.Schema); ok {
+			out = append(out, additional)
+		}
+		out = append(out, s.PrefixItems...)
+		out = append(out, s.Items2020)
+		for _, prop := range s.Properties {
+			out = append(out, prop)
+		}
+	} else if prop, ok := s.Properties[segment]; ok {
+		out = append(out, prop)
+	}
+	for pattern, prop := range s.PatternProperties {
+		if segment == ItemSegment || pattern.MatchString(segment) {
+			out = append(out, prop)
+		}
+	}
+	switch additional := s.AdditionalProperties.(type) {
+	case *jsonschema.Schema:
+		out = append(out, additional)
+	case bool:
+		if additional {
+			out = append(out, &jsonschema.Schema{})
+		}
+	}
+	return out
+}
+
+// related returns the schemas that also apply to the value of s.
+func related(s *jsonschema.Schema) []*jsonschema.Schema {
+	var out []*jsonschema.Schema
+	for _, ref := range []*jsonschema.Schema{s.Ref, s.RecursiveRef, s.If, s.Then, s.Else} {
+		if ref != nil {
+			out = append(out, ref)
+		}
+	}
+	if s.DynamicRef != nil && s.DynamicRef.Ref != nil {
+		out = append(out, s.DynamicRef.Ref)
+	}
+	out = append(out, s.AllOf...)
+	out = append(out, s.AnyOf...)
+	return append(out, s.OneOf...)
+}
@@ -0,0 +1,51 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledDeclares(t *testing.T) {
+	root := map[string]any{
+		"type": "object",
+		"$defs": map[string]any{
+			"node": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}, "children": map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/node"}}},
+			},
+		},
+		"properties": map[string]any{
+			"name":   map[string]any{"type": "string"},
+			"tree":   map[string]any{"$ref": "#/$defs/node"},
+			"extra":  map[string]any{},
+			"labels": map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"ports":  map[string]any{"type": "object", "patternProperties": map[string]any{"^p[0-9]+$": map[string]any{"type": "integer"}}},
+		},
+		"allOf": []any{map[string]any{"properties": map[string]any{"fromAllOf": map[string]any{"type": "string"}}}},
+	}
+	compiled, err := Compile("file:///schemas/root.json", root, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     []string
+		expected bool
+	}{
+		{nil, true},
+		{[]string{"name"}, true},
+		{[]string{"missing"}, false},
+		{[]string{"name", "first"}, false},
+		{[]string{"tree", "children", ItemSegment, "children", ItemSegment, "name"}, true},
+		{[]string{"tree", "children", ItemSegment, "age"}, false},
+		{[]string{"extra", "anything", "below"}, true},
+		{[]string{"labels", "app"}, true},
+		{[]string{"labels", ItemSegment}, true},
+		{[]string{"ports", "p80"}, true},
+		{[]string{"ports", "http"}, false},
+		{[]string{"fromAllOf"}, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, compiled.Declares(tt.path), "%v", tt.path)
+	}
+}
@@ -0,0 +1,110 @@
+package schema
+
+import "encoding/json"
+
+// Draft202012 is the $schema of inferred schemas.
+const Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// Usage records how templates use a value in their data, to infer a schema from.
+type Usage struct {
+	Properties map[string]*Usage // fields accessed on the value
+	Items      *Usage            // how the elements are used, when the value is ranged over or indexed by number
+	Ranged     bool              // the value is ranged over, so it is a list or an object
+}
+
+// NewUsage returns an empty Usage.
+func NewUsage() *Usage {
+	return &Usage{}
+}
+
+// Field returns the usage of the named field of the value, recording that it is accessed.
+func (u *Usage) Field(name string) *Usage {
+	if u.Properties == nil {
+		u.Properties = make(map[string]*Usage)
+	}
+	field, ok := u.Properties[name]
+	if !ok {
+		field = NewUsage()
+		u.Properties[name] = field
+	}
+	return field
+}
+
+// Item returns the usage of the elements of the value.
+func (u *Usage) Item() *Usage {
+	if u.Items == nil {
+		u.Items = NewUsage()
+	}
+	return u.Items
+}
+
+// Inferred is a JSON Schema inferred from a Usage. An empty Inferred accepts any value and marshals as true.
+type Inferred struct {
+	Schema               string   // $schema
+	Types                []string // type, a string when there is only one
+	Properties           map[string]*Inferred
+	Items                *Inferred
+	AdditionalProperties *Inferred
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *Inferred) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any)
+	if s.Schema != "" {
+		out["$schema"] = s.Schema
+	}
+	switch len(s.Types) {
+	case 0:
+	case 1:
+		out["type"] = s.Types[0]
+	default:
+		out["type"] = s.Types
+	}
+	if s.Properties != nil {
+		out["properties"] = s.Properties
+	}
+	if s.Items != nil {
+		out["items"] = s.Items
+	}
+	if s.AdditionalProperties != nil {
+		out["additionalProperties"] = s.AdditionalProperties
+	}
+	if len(out) == 0 {
+		return []byte("true"), nil
+	}
+	return json.Marshal(out)
+}
+
+// Infer returns a skeleton JSON Schema of data used as recorded by u. Values with accessed fields are
+// objects, values that are ranged over are lists or objects of their elements, and values that are only
+// output are left unconstrained to be refined by hand.
+func Infer(u *Usage) *Inferred {
+	s := infer(u)
+	s.Schema = Draft202012
+	s.Types = []string{"object"}
+	return s
+}
+
+func infer(u *Usage) *Inferred {
+	s := &Inferred{}
+	switch {
+	case len(u.Properties) > 0:
+		s.Types = []string{"object"}
+		s.Properties = make(map[string]*Inferred, len(u.Properties))
+		for name, field := range u.Properties {
+			s.Properties[name] = infer(field)
+		}
+		if u.Items != nil {
+			s.AdditionalProperties = infer(u.Items)
+		}
+	case u.Ranged || u.Items != nil:
+		s.Types = []string{"array", "object"}
+		item := &Inferred{}
+		if u.Items != nil {
+			item = infer(u.Items)
+		}
+		s.Items = item
+		s.AdditionalProperties = item
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfer(t *testing.T) {
+	usage := NewUsage()
+	usage.Field("name")
+	image := usage.Field("image")
+	image.Field("tag")
+	hosts := usage.Field("hosts")
+	hosts.Ranged = true
+	hosts.Item().Field("address")
+	usage.Field("ports").Item()
+
+	s := Infer(usage)
+	assert.Equal(t, Draft202012, s.Schema)
+	assert.Equal(t, []string{"object"}, s.Types)
+	assert.Equal(t, []string{"object"}, s.Properties["image"].Types)
+	assert.Contains(t, s.Properties["image"].Properties, "tag")
+	assert.Equal(t, []string{"array", "object"}, s.Properties["hosts"].Types)
+	assert.Equal(t, []string{"object"}, s.Properties["hosts"].Items.Types)
+	assert.Equal(t, []string{"array", "object"}, s.Properties["ports"].Types)
+
+	// the inferred schema accepts data shaped the way the templates use it
+	compiled, err := Compile("file:///inferred.json", s, nil)
+	require.NoError(t, err)
+	violations, err := compiled.Validate(map[string]any{
+		"name":  "app",
+		"image": map[string]any{"tag": "1.0"},
+		"hosts": []any{map[string]any{"address": "localhost"}},
+		"ports": map[string]any{"http": 80},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = compiled.Validate(map[string]any{"image": "nginx:1.0"})
+	require.NoError(t, err)
+	assert.Len(t, violations, 1)
+}
@@ -1,29 +1,57 @@
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
 
+// The helpers in this file are the API of this package from before Compile, kept so that library users can move
+// off them. Their signatures use the types of github.com/google/jsonschema-go, so they and that dependency are
+// removed together in 1.0.0.
+
+// ResolveSchema applies the defaults of schema to data and validates it, returning data.
+//
+// Deprecated: use Compile, then Compiled.ApplyDefaults and Compiled.Validate, which support every draft,
+// $refs to other schemas and report every violation.
 func ResolveSchema(data any, schema []byte) (any, error) {
-	s, err := LoadSchema(schema)
-	if err != nil {
-		return nil, fmt.Errorf("load schema error: %w", err)
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("load schema error: schema is empty")
 	}
-
-	r, err := ApplyDefaults(data, s)
+	var doc any
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, fmt.Errorf("load schema error: unmarshal schema error: %w", err)
+	}
+	c, err := Compile("schema.json", doc, nil)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("resolve schema error: %w", err)
 	}
-
-	err = r.Validate(data)
+	if err = c.ApplyDefaults(data); err != nil {
+		return nil, fmt.Errorf("apply defaults error: %w", err)
+	}
+	violations, err := c.Validate(data)
 	if err != nil {
 		return nil, fmt.Errorf("validate error: %w", err)
 	}
+	if len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			pointer := v.Pointer
+			if pointer == "" {
+				pointer = "/"
+			}
+			messages[i] = pointer + ": " + v.Message
+		}
+		return nil, fmt.Errorf("validate error: %s", strings.Join(messages, "; "))
+	}
 	return data, nil
 }
 
+// ApplyDefaults resolves s and applies its defaults to data.
+//
+// Deprecated: use Compiled.ApplyDefaults.
 func ApplyDefaults(data any, s *jsonschema.Schema) (*jsonschema.Resolved, error) {
 	r, err := s.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
 	if err != nil {
@@ -37,6 +65,8 @@ func ApplyDefaults(data any, s *jsonschema.Schema) (*jsonschema.Resolved, error)
 }
 
 // LoadSchema loads a schema from a byte array and returns the jsonschema Schema.
+//
+// Deprecated: use Compile.
 func LoadSchema(schema []byte) (r *jsonschema.Schema, err error) {
 	s := jsonschema.Schema{}
 	if len(schema) == 0 {
@@ -50,6 +80,9 @@ func LoadSchema(schema []byte) (r *jsonschema.Schema, err error) {
 	return &s, nil
 }
 
+// NestSchema returns an object schema with schema as the property key.
+//
+// Deprecated: nest the schema document before calling Compile.
 func NestSchema(schema *jsonschema.Schema, key string) *jsonschema.Schema {
 	newSchema := jsonschema.Schema{
 		Type:       "object",
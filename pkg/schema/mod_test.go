@@ -139,7 +139,7 @@ func TestResolveSchema(t *testing.T) {
 				}`,
 			},
 			want: nil,
-			err:  `validate error: validating root: validating /properties/age: type: 38 has type "string", want "integer"`,
+			err:  `validate error: /age: got string, want integer`,
 		},
 	}
 	for _, tt := range tests {
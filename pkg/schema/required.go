@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Field is a required object property that is missing from validated data.
+type Field struct {
+	Path        []string // object keys from the root of the validated data to the property
+	Title       string
+	Description string
+	Types       []string // the JSON types the value can have, empty for any
+	Enum        []any    // the values the value is limited to, empty for any
+	Default     any
+	HasDefault  bool
+}
+
+// Pointer returns the JSON pointer to the field in the validated data.
+func (f Field) Pointer() string {
+	return jsonPointer(f.Path)
+}
+
+// MissingRequired returns the required properties missing from instance, in the order the schema lists
+// them, recursing into objects and following $ref and allOf. A missing object that has required properties
+// of its own is replaced by those properties, so only values that can be entered directly are returned.
+func (c *Compiled) MissingRequired(instance any) []Field {
+	var fields []Field
+	missingRequired(instance, c.schema, nil, make(map[*jsonschema.Schema]bool), &fields)
+	return fields
+}
+
+// missingRequired adds the required properties of s missing from instance to fields. A nil instance is an
+// object that is missing entirely.
+func missingRequired(instance any, s *jsonschema.Schema, path []string, visiting map[*jsonschema.Schema]bool, fields *[]Field) {
+	obj, ok := instance.(map[string]any)
+	if (!ok && instance != nil) || s == nil || visiting[s] {
+		return
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	for _, prop := range s.Required {
+		if _, exists := obj[prop]; exists {
+			continue
+		}
+		propPath := append(slices.Clone(path), prop)
+		if slices.ContainsFunc(*fields, func(f Field) bool { return slices.Equal(f.Path, propPath) }) {
+			continue
+		}
+		field := fieldOf(s.Properties[prop], propPath)
+		if slices.Equal(field.Types, []string{"object"}) && !field.HasDefault {
+			before := len(*fields)
+			missingRequired(nil, s.Properties[prop], propPath, visiting, fields)
+			if len(*fields) > before {
+				continue
+			}
+		}
+		*fields = append(*fields, field)
+	}
+	for _, prop := range slices.Sorted(maps.Keys(s.Properties)) {
+		if value, exists := obj[prop]; exists {
+			missingRequired(value, s.Properties[prop], append(slices.Clone(path), prop), visiting, fields)
+		}
+	}
+	related := slices.Clone(s.AllOf)
+	if s.Ref != nil {
+		related = append(related, s.Ref)
+	}
+	for _, sub := range related {
+		missingRequired(instance, sub, path, visiting, fields)
+	}
+}
+
+// fieldOf describes the property at path with schema s, taking anything s doesn't set from the schema
+// it references.
+func fieldOf(s *jsonschema.Schema, path []string) Field {
+	field := Field{Path: path}
+	for depth := 0; s != nil && depth < 32; depth++ {
+		if field.Title == "" {
+			field.Title = strings.TrimSpace(s.Title)
+		}
+		if field.Description == "" {
+			field.Description = strings.TrimSpace(s.Description)
+		}
+		if len(field.Types) == 0 && s.Types != nil {
+			field.Types = s.Types.ToStrings()
+		}
+		if len(field.Enum) == 0 && s.Enum != nil {
+			for _, value := range s.Enum.Values {
+				if v, ok := jsonValue(value); ok {
+					field.Enum = append(field.Enum, v)
+				}
+			}
+		}
+		if !field.HasDefault && s.Default != nil {
+			field.Default, field.HasDefault = jsonValue(*s.Default)
+		}
+		s = s.Ref
+	}
+	return field
+}
+
+// jsonValue round trips a value of the compiler through JSON, so it decodes the same as data.
+func jsonValue(v any) (any, bool) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var value any
+	if err = json.Unmarshal(b, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiledMissingRequired(t *testing.T) {
+	root := map[string]any{
+		"type":     "object",
+		"required": []any{"name", "license", "project", "db"},
+		"$defs": map[string]any{
+			"license": map[string]any{"description": "License to use", "enum": []any{"MIT", "ISC"}, "default": "MIT"},
+		},
+		"properties": map[string]any{
+			"name":    map[string]any{"type": "string", "title": "Name"},
+			"license": map[string]any{"$ref": "#/$defs/license"},
+			"project": map[string]any{
+				"type":     "object",
+				"required": []any{"python", "private"},
+				"properties": map[string]any{
+					"python":  map[string]any{"type": "string"},
+					"private": map[string]any{"type": "boolean"},
+				},
+			},
+			"db": map[string]any{
+				"type":     "object",
+				"required": []any{"host"},
+			},
+		},
+	}
+	compiled, err := Compile("file:///schemas/root.json", root, nil)
+	require.NoError(t, err)
+
+	fields := compiled.MissingRequired(map[string]any{
+		"project": map[string]any{"private": true},
+		"db":      map[string]any{"host": "localhost"},
+	})
+	assert.Equal(t, []Field{
+		{Path: []string{"name"}, Title: "Name", Types: []string{"string"}},
+		{Path: []string{"license"}, Description: "License to use", Enum: []any{"MIT", "ISC"}, Default: "MIT", HasDefault: true},
+		{Path: []string{"project", "python"}, Types: []string{"string"}},
+	}, fields)
+	assert.Equal(t, "/project/python", fields[2].Pointer())
+
+	// missing objects are replaced by their required properties
+	fields = compiled.MissingRequired(map[string]any{"name": "app", "license": "MIT"})
+	var pointers []string
+	for _, f := range fields {
+		pointers = append(pointers, f.Pointer())
+	}
+	assert.Equal(t, []string{"/project/python", "/project/private", "/db/host"}, pointers)
+}
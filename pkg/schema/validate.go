@@ -0,0 +1,178 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Violation is a single reason data does not validate against a schema.
+type Violation struct {
+	Pointer string // JSON pointer to the invalid value in the validated data, "" for the root
+	Message string
+}
+
+// Compiled is a schema, of any JSON Schema draft, compiled together with the schemas it references.
+type Compiled struct {
+	schema *jsonschema.Schema
+}
+
+// LoaderFunc loads the schema document at an absolute URL, decoded as JSON.
+type LoaderFunc func(url string) (any, error)
+
+// Load implements the URL loader interface of the compiler.
+func (l LoaderFunc) Load(url string) (any, error) {
+	doc, err := l(url)
+	if err != nil {
+		return nil, err
+	}
+	return toJSONValue(doc)
+}
+
+// Compile compiles the schema document doc that was loaded from the absolute URL location. Schemas
+// referenced with $ref that are not part of doc are loaded with load, relative refs being resolved
+// against location. Drafts 4, 6, 7, 2019-09 and 2020-12 are supported, 2020-12 if $schema is not set.
+func Compile(location string, doc any, load LoaderFunc) (*Compiled, error) {
+	normalized, err := toJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	c := jsonschema.NewCompiler()
+	if load != nil {
+		c.UseLoader(load)
+	}
+	if err = c.AddResource(location, normalized); err != nil {
+		return nil, err
+	}
+	compiled, err := c.Compile(location)
+	if err != nil {
+		return nil, err
+	}
+	return &Compiled{schema: compiled}, nil
+}
+
+// Validate validates instance against the schema and returns every violation, ordered by location, or nil
+// if it is valid.
+func (c *Compiled) Validate(instance any) ([]Violation, error) {
+	normalized, err := toJSONValue(instance)
+	if err != nil {
+		return nil, err
+	}
+	err = c.schema.Validate(normalized)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, err
+	}
+	var violations []Violation
+	collectViolations(validationErr, &violations)
+	// the order of the causes follows map iteration, sort to report the same order every run
+	slices.SortStableFunc(violations, func(a, b Violation) int {
+		return strings.Compare(a.Pointer, b.Pointer)
+	})
+	return violations, nil
+}
+
+var messagePrinter = message.NewPrinter(language.English)
+
+// collectViolations adds the leaves of the error tree to violations. anyOf and oneOf failures are reported
+// as a whole rather than listing why each of their alternatives failed.
+func collectViolations(e *jsonschema.ValidationError, violations *[]Violation) {
+	switch e.ErrorKind.(type) {
+	case *kind.AnyOf, *kind.OneOf:
+	default:
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				collectViolations(cause, violations)
+			}
+			return
+		}
+	}
+	violation := Violation{
+		Pointer: jsonPointer(e.InstanceLocation),
+		Message: e.ErrorKind.LocalizedString(messagePrinter),
+	}
+	if !slices.Contains(*violations, violation) {
+		*violations = append(*violations, violation)
+	}
+}
+
+// jsonPointer formats path segments as a JSON pointer (RFC 6901).
+func jsonPointer(segments []string) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// ApplyDefaults sets object properties that are missing from instance, and are not required, to their
+// defaults in the schema, recursing into objects and following $ref and allOf.
+func (c *Compiled) ApplyDefaults(instance any) error {
+	return applyDefaults(instance, c.schema, make(map[*jsonschema.Schema]bool))
+}
+
+func applyDefaults(instance any, s *jsonschema.Schema, visiting map[*jsonschema.Schema]bool) error {
+	obj, ok := instance.(map[string]any)
+	if !ok || s == nil || visiting[s] {
+		return nil
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	for _, prop := range slices.Sorted(maps.Keys(s.Properties)) {
+		propSchema := s.Properties[prop]
+		if _, exists := obj[prop]; !exists && !slices.Contains(s.Required, prop) {
+			if value, found := defaultOf(propSchema); found {
+				obj[prop] = value
+			}
+		}
+		if value, exists := obj[prop]; exists {
+			if err := applyDefaults(value, propSchema, visiting); err != nil {
+				return err
+			}
+		}
+	}
+	related := slices.Clone(s.AllOf)
+	if s.Ref != nil {
+		related = append(related, s.Ref)
+	}
+	for _, sub := range related {
+		if err := applyDefaults(obj, sub, visiting); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultOf returns the default of a schema or the schema it references, decoded the same as data.
+func defaultOf(s *jsonschema.Schema) (any, bool) {
+	for depth := 0; s != nil && depth < 32; depth++ {
+		if s.Default != nil {
+			return jsonValue(*s.Default)
+		}
+		s = s.Ref
+	}
+	return nil, false
+}
+
+// toJSONValue converts decoded data (which may hold yaml or toml types) to the values of decoded JSON.
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert to json: %w", err)
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(b))
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompiledValidate(t *testing.T) {
+	refs := map[string]any{
+		"file:///schemas/db.json": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"host": map[string]any{"type": "string", "default": "localhost"},
+				"port": map[string]any{"type": "integer", "minimum": 1},
+			},
+		},
+	}
+	load := func(url string) (any, error) {
+		if doc, ok := refs[url]; ok {
+			return doc, nil
+		}
+		return nil, fmt.Errorf("not found: %s", url)
+	}
+	root := map[string]any{
+		"$schema":  "http://json-schema.org/draft-07/schema#",
+		"type":     "object",
+		"required": []any{"name"},
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string"},
+			"port":  map[string]any{"type": "integer", "default": 8080},
+			"db":    map[string]any{"$ref": "db.json"},
+			"hosts": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+
+	compiled, err := Compile("file:///schemas/root.json", root, load)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		instance     map[string]any
+		withDefaults map[string]any
+		violations   []Violation
+	}{
+		{
+			name:         "valid with defaults from a referenced schema",
+			instance:     map[string]any{"name": "app", "db": map[string]any{}},
+			withDefaults: map[string]any{"name": "app", "port": float64(8080), "db": map[string]any{"host": "localhost"}},
+		},
+		{
+			name:     "all violations are collected",
+			instance: map[string]any{"port": "x", "db": map[string]any{"port": uint64(0)}, "hosts": []any{"a", 1}},
+			violations: []Violation{
+				{Pointer: "", Message: "missing property 'name'"},
+				{Pointer: "/db/port", Message: "minimum: got 0, want 1"},
+				{Pointer: "/hosts/1", Message: "got number, want string"},
+				{Pointer: "/port", Message: "got string, want integer"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, compiled.ApplyDefaults(tt.instance))
+			if tt.withDefaults != nil {
+				assert.Equal(t, tt.withDefaults, tt.instance)
+			}
+			violations, err := compiled.Validate(tt.instance)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.violations, violations)
+		})
+	}
+
+	_, err = Compile("file:///schemas/broken.json", map[string]any{"$ref": "missing.json"}, load)
+	assert.ErrorContains(t, err, "not found: file:///schemas/missing.json")
+}
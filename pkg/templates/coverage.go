@@ -0,0 +1,197 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/adam-huganir/yutc/pkg/coverage"
+)
+
+// coverFunc is the function called by the actions Instrument adds, with the index of the block to count.
+const coverFunc = "yutcCover"
+
+// Instrument adds an action counting the executions of each block of the templates of the set into profile:
+// the bodies of the template files and defines, and the branches of if, range and with, including the implicit
+// else of those without one. It must be called before the set is executed.
+func Instrument(ts *TemplateSet, profile *coverage.Profile) error {
+	c := &coverer{profile: profile}
+	ts.Template.Funcs(template.FuncMap{coverFunc: func(id int) string {
+		c.blocks[id].Count++
+		return ""
+	}})
+
+	rendered := make(map[string]bool, len(ts.TemplateFiles))
+	for _, item := range ts.TemplateFiles {
+		rendered[item.Template.NewName] = true
+	}
+	instrumented := make(map[*parse.Tree]bool)
+	for _, t := range ts.Template.Templates() {
+		tree := t.Tree
+		if tree == nil || tree.Root == nil || instrumented[tree] {
+			continue
+		}
+		instrumented[tree] = true
+		source, ok := ts.sources[tree.ParseName]
+		if !ok {
+			continue
+		}
+		profile.AddSource(source.path, source.frontMatter+source.text)
+		c.source = source
+		var err error
+		switch {
+		case t.Name() != tree.ParseName:
+			// the body starts after the define action
+			err = c.block(tree.Root, actionStart(source.text, tree.Root.Pos), fmt.Sprintf("define %q", t.Name()))
+		case rendered[t.Name()]:
+			err = c.block(tree.Root, 0, "template")
+		default:
+			// shared templates are only parsed for their defines
+			err = c.nested(tree.Root)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type coverer struct {
+	profile *coverage.Profile
+	blocks  []*coverage.Block // by the index passed to coverFunc
+	source  templateSource    // of the tree being instrumented
+}
+
+// block instruments list as a block starting at pos, then the blocks nested in it.
+func (c *coverer) block(list *parse.ListNode, pos parse.Pos, label string) error {
+	line, column := c.position(pos)
+	cover, err := c.coverList(&coverage.Block{
+		Path:   c.source.path,
+		Line:   line,
+		Column: column,
+		Label:  label,
+		Lines:  c.lines(list),
+	})
+	if err != nil {
+		return err
+	}
+	if err = c.nested(list); err != nil {
+		return err
+	}
+	list.Nodes = append(cover.Nodes, list.Nodes...)
+	return nil
+}
+
+// nested instruments the branches of the if, range and with actions in list.
+func (c *coverer) nested(list *parse.ListNode) error {
+	for _, node := range list.Nodes {
+		var err error
+		switch n := node.(type) {
+		case *parse.IfNode:
+			err = c.branches(&n.BranchNode, "if")
+		case *parse.RangeNode:
+			err = c.branches(&n.BranchNode, "range")
+		case *parse.WithNode:
+			err = c.branches(&n.BranchNode, "with")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *coverer) branches(n *parse.BranchNode, keyword string) error {
+	label := keyword + " " + n.Pipe.String()
+	start := actionStart(c.source.text, n.Pos)
+	if err := c.block(n.List, start, label); err != nil {
+		return err
+	}
+	switch {
+	case n.ElseList == nil:
+		line, column := c.position(start)
+		cover, err := c.coverList(&coverage.Block{Path: c.source.path, Line: line, Column: column, Label: "implicit else of " + label})
+		if err != nil {
+			return err
+		}
+		n.ElseList = cover
+		return nil
+	case isElseChain(n.ElseList):
+		// else if and else with are counted as their own branches
+		return c.nested(n.ElseList)
+	default:
+		return c.block(n.ElseList, actionStart(c.source.text, n.ElseList.Pos), "else of "+label)
+	}
+}
+
+// isElseChain reports whether list is the body of an else if or else with, which the parser nests as the only
+// node of the else list.
+func isElseChain(list *parse.ListNode) bool {
+	if len(list.Nodes) != 1 {
+		return false
+	}
+	switch list.Nodes[0].(type) {
+	case *parse.IfNode, *parse.WithNode:
+		return true
+	}
+	return false
+}
+
+// coverList adds block to the profile and returns a list made of the action counting it.
+func (c *coverer) coverList(block *coverage.Block) (*parse.ListNode, error) {
+	id := len(c.blocks)
+	c.blocks = append(c.blocks, c.profile.Block(block))
+	trees, err := parse.Parse(coverFunc, fmt.Sprintf("{{%s %d}}", coverFunc, id), "{{", "}}", map[string]any{coverFunc: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to instrument %s: %w", c.source.path, err)
+	}
+	return trees[coverFunc].Root, nil
+}
+
+// lines returns the lines holding the text and actions of list itself, not of the blocks nested in it.
+func (c *coverer) lines(list *parse.ListNode) []int {
+	var lines []int
+	add := func(line int) {
+		if len(lines) == 0 || lines[len(lines)-1] < line {
+			lines = append(lines, line)
+		}
+	}
+	for _, node := range list.Nodes {
+		if int(node.Position()) > len(c.source.text) {
+			continue
+		}
+		first, _ := c.position(node.Position())
+		text, ok := node.(*parse.TextNode)
+		if !ok {
+			add(first)
+			continue
+		}
+		for i, line := range strings.Split(string(text.Text), "\n") {
+			if strings.TrimSpace(line) != "" {
+				add(first + i)
+			}
+		}
+	}
+	return lines
+}
+
+// position returns the line and column, from 1, of pos in the source.
+func (c *coverer) position(pos parse.Pos) (int, int) {
+	if int(pos) > len(c.source.text) {
+		return 0, 0
+	}
+	before := c.source.text[:pos]
+	return c.source.lineOffset() + strings.Count(before, "\n") + 1, int(pos) - strings.LastIndex(before, "\n")
+}
+
+// actionStart returns the position of the {{ of the action containing pos.
+func actionStart(text string, pos parse.Pos) parse.Pos {
+	if int(pos) > len(text) {
+		return pos
+	}
+	if start := strings.LastIndex(text[:pos], "{{"); start >= 0 {
+		return parse.Pos(start)
+	}
+	return pos
+}
@@ -0,0 +1,61 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/coverage"
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coverTemplates renders template, with the defines of shared, once per data value into one profile and returns
+// the blocks of the profile as `path:line:col count label lines`.
+func coverTemplates(t *testing.T, template, shared string, data ...map[string]any) ([]string, []string) {
+	t.Helper()
+	profile := coverage.NewProfile()
+	logger := zerolog.Nop()
+	var outputs []string
+	for _, d := range data {
+		item := NewInput("main", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(template)))
+		sharedInputs := []*Input{NewInput("shared", true, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(shared)))}
+		ts, err := LoadTemplateSet([]*Input{item}, sharedInputs, d, false, false, "", false, &logger)
+		require.NoError(t, err)
+		require.NoError(t, Instrument(ts, profile))
+		var out bytes.Buffer
+		require.NoError(t, ts.Template.ExecuteTemplate(&out, "main", d))
+		outputs = append(outputs, out.String())
+	}
+	var blocks []string
+	for _, f := range profile.Files() {
+		for _, b := range f.Blocks {
+			blocks = append(blocks, fmt.Sprintf("%s:%d:%d %d %s %v", b.Path, b.Line, b.Column, b.Count, b.Label, b.Lines))
+		}
+	}
+	return blocks, outputs
+}
+
+func TestInstrument(t *testing.T) {
+	blocks, outputs := coverTemplates(t,
+		"a: {{ include \"name\" . }}\n{{- if .on }}\nb: on\n{{- else if .off }}\nb: off\n{{- else }}\nb: unset\n{{- end }}\n{{ range .items }}- {{ . }}\n{{ end }}",
+		"{{ define \"name\" }}{{ with .name }}{{ . }}{{ end }}{{ end }}\n{{ define \"unused\" }}x{{ end }}",
+		map[string]any{"on": true, "name": "x", "items": []any{1, 2}},
+		map[string]any{"off": true},
+	)
+	assert.Equal(t, []string{"a: x\nb: on\n- 1\n- 2\n", "a: \nb: off\n"}, outputs, "instrumenting must not change the output")
+	assert.Equal(t, []string{
+		"main:1:1 2 template [1 2 9]",
+		"main:2:1 1 if .on [3]",
+		"main:4:1 1 if .off [5]",
+		"main:6:1 0 else of if .off [7]",
+		"main:9:1 2 range .items [9]",
+		"main:9:1 1 implicit else of range .items []",
+		"shared:1:1 2 define \"name\" [1]",
+		"shared:1:20 1 with .name [1]",
+		"shared:1:20 1 implicit else of with .name []",
+		"shared:2:1 0 define \"unused\" [2]",
+	}, blocks)
+}
@@ -0,0 +1,209 @@
+package templates
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/adam-huganir/yutc/pkg/types"
+)
+
+// snippetContext is the number of lines shown before and after the line of a template error.
+const snippetContext = 2
+
+// tplSourceName is the name template errors report for the text rendered by the tpl function.
+const tplSourceName = "tpl"
+
+// templateSource is the text of a parsed template and the path its errors are reported with.
+type templateSource struct {
+	path        string
+	text        string
+	frontMatter string // stripped from the start of the file before text, counted in the lines reported
+}
+
+// lineOffset returns the number of lines of the file before text.
+func (s templateSource) lineOffset() int {
+	return strings.Count(s.frontMatter, "\n")
+}
+
+var (
+	// errorFrameRE matches the location prefix text/template adds to parse and execution errors.
+	errorFrameRE = regexp.MustCompile(`^template: (.+?):(\d+)(?::(\d+))?: (?:executing "(?:[^"\\]|\\.)*" at <(.*?)>: )?`)
+	// callDotRE matches include and tpl calls, capturing the data they are given.
+	callDotRE    = regexp.MustCompile(`^(?:include|tpl) .* (\S+)$`)
+	fieldChainRE = regexp.MustCompile(`^(\$?)((?:\.[\w-]+)+|\.)$`)
+	nilFieldRE   = regexp.MustCompile(`nil pointer evaluating .*\.([\w-]+)$`)
+	missingKeyRE = regexp.MustCompile(`map has no entry for key "([^"]*)"$`)
+)
+
+// errorFrame is a template location found in an error message.
+type errorFrame struct {
+	name       string
+	line       int
+	column     int // from 1, 0 if unknown
+	expression string
+	tplText    *string // the text given to tpl, for frames in it
+}
+
+// parseErrorChain splits the message of a text/template error into the locations it passed through,
+// outermost first, following include and tpl calls, and the message of the innermost error.
+func parseErrorChain(msg string) ([]errorFrame, string) {
+	var frames []errorFrame
+	var tplText *string
+	rest := msg
+	for {
+		m := errorFrameRE.FindStringSubmatch(rest)
+		if m == nil {
+			break
+		}
+		frame := errorFrame{name: m[1], expression: m[4], tplText: tplText}
+		frame.line, _ = strconv.Atoi(m[2])
+		if m[3] != "" {
+			// text/template reports the byte offset in the line
+			offset, _ := strconv.Atoi(m[3])
+			frame.column = offset + 1
+		}
+		frames = append(frames, frame)
+		rest = rest[len(m[0]):]
+		tplText = nil
+
+		if after, ok := strings.CutPrefix(rest, "error calling include: "); ok {
+			rest = after
+			continue
+		}
+		if after, ok := strings.CutPrefix(rest, "error calling tpl: "); ok {
+			rest = after
+			for _, prefix := range []string{"error during tpl function execution for ", "cannot parse template "} {
+				if after, ok = strings.CutPrefix(rest, prefix); !ok {
+					continue
+				}
+				quoted, err := strconv.QuotedPrefix(after)
+				if err != nil {
+					break
+				}
+				text, err := strconv.Unquote(quoted)
+				if err != nil {
+					break
+				}
+				tplText = &text
+				rest = strings.TrimPrefix(after[len(quoted):], ": ")
+				break
+			}
+			continue
+		}
+		break
+	}
+	return frames, rest
+}
+
+// newTemplateError describes err, from parsing or executing the template at templatePath, with the location
+// of the error in sources, the template sources by name, the data that was being evaluated and the include
+// and tpl calls leading to it.
+func newTemplateError(err error, templatePath string, sources map[string]templateSource) *types.TemplateError {
+	te := &types.TemplateError{TemplatePath: templatePath, Message: err.Error(), Err: err}
+	frames, message := parseErrorChain(err.Error())
+	if len(frames) == 0 {
+		return te
+	}
+	te.Message = message
+
+	// dot is the path of the data given to the current template, known while the calls pass field chains
+	dot, dotKnown := "", true
+	for i, frame := range frames {
+		source, ok := sources[frame.name]
+		if frame.tplText != nil {
+			source, ok = templateSource{path: tplSourceName, text: *frame.tplText}, true
+		} else if !ok {
+			source.path = frame.name
+		}
+		frame.line += source.lineOffset()
+		if i < len(frames)-1 {
+			te.Stack = append(te.Stack, types.TemplateFrame{
+				TemplatePath: source.path,
+				Line:         frame.line,
+				Column:       frame.column,
+				Expression:   frame.expression,
+			})
+			dot, dotKnown = callDot(dot, dotKnown, frame.expression)
+			continue
+		}
+		te.TemplatePath = source.path
+		te.Line = frame.line
+		te.Column = frame.column
+		te.Expression = frame.expression
+		if ok {
+			te.Snippet = snippet(source.frontMatter+source.text, frame.line)
+		}
+		if dotKnown {
+			te.DataPath = dataPath(dot, frame.expression)
+		}
+	}
+	te.Hint = dataHint(te.DataPath, message)
+	return te
+}
+
+// callDot returns the path of the data an include or tpl call passes to the template it renders.
+func callDot(dot string, known bool, expression string) (string, bool) {
+	m := callDotRE.FindStringSubmatch(expression)
+	if m == nil || !known {
+		return "", false
+	}
+	path := dataPath(dot, m[1])
+	return path, path != ""
+}
+
+// dataPath returns the path from the root of the data of a field chain evaluated with dot at the path
+// dot, or "" if expression is not a field chain.
+func dataPath(dot, expression string) string {
+	m := fieldChainRE.FindStringSubmatch(expression)
+	if m == nil {
+		return ""
+	}
+	if m[2] == "." {
+		m[2] = ""
+	}
+	if m[1] == "$" || dot == "." {
+		dot = ""
+	}
+	if path := dot + m[2]; path != "" {
+		return path
+	}
+	return "."
+}
+
+// dataHint explains which part of the data at path caused the error message, ex: ".Values.image is nil".
+func dataHint(path, message string) string {
+	if path == "" {
+		return ""
+	}
+	if m := nilFieldRE.FindStringSubmatch(message); m != nil {
+		if parent, ok := strings.CutSuffix(path, "."+m[1]); ok {
+			if parent == "" {
+				parent = "."
+			}
+			return parent + " is nil"
+		}
+	}
+	if m := missingKeyRE.FindStringSubmatch(message); m != nil {
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			if i > 0 && segment == m[1] {
+				return strings.Join(segments[:i+1], ".") + " is missing"
+			}
+		}
+	}
+	return ""
+}
+
+// snippet returns the lines of text around line.
+func snippet(text string, line int) []types.SourceLine {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if line < 1 || line > len(lines) {
+		return nil
+	}
+	var out []types.SourceLine
+	for n := max(1, line-snippetContext); n <= min(len(lines), line+snippetContext); n++ {
+		out = append(out, types.SourceLine{Number: n, Text: strings.TrimSuffix(lines[n-1], "\r")})
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"text/template"
+)
+
+func TestParseErrorChain(t *testing.T) {
+	msg := `template: main:3:8: executing "main" at <include "inner" .Values>: error calling include: ` +
+		`template: shared-0:1:22: executing "inner" at <tpl "{{ .x.y }}" .>: error calling tpl: ` +
+		`error during tpl function execution for "{{ .x.y }}": template: yutc:1:5: executing "yutc" at <.x.y>: ` +
+		`nil pointer evaluating interface {}.y`
+	frames, message := parseErrorChain(msg)
+	require.Len(t, frames, 3)
+	assert.Equal(t, "nil pointer evaluating interface {}.y", message)
+	assert.Equal(t, errorFrame{name: "main", line: 3, column: 9, expression: `include "inner" .Values`}, frames[0])
+	assert.Equal(t, errorFrame{name: "shared-0", line: 1, column: 23, expression: `tpl "{{ .x.y }}" .`}, frames[1])
+	require.NotNil(t, frames[2].tplText)
+	assert.Equal(t, "{{ .x.y }}", *frames[2].tplText)
+	assert.Equal(t, ".x.y", frames[2].expression)
+
+	frames, message = parseErrorChain(`template: main:2: function "nope" not defined`)
+	assert.Equal(t, []errorFrame{{name: "main", line: 2}}, frames)
+	assert.Equal(t, `function "nope" not defined`, message)
+
+	frames, message = parseErrorChain("something else")
+	assert.Empty(t, frames)
+	assert.Equal(t, "something else", message)
+}
+
+func TestNewTemplateError(t *testing.T) {
+	shared := `{{ define "image" }}image: {{ .image.tag }}{{ end }}`
+	tests := []struct {
+		name     string
+		template string
+		data     map[string]any
+		strict   bool
+		expected types.TemplateError
+	}{
+		{
+			name:     "nil parent in include",
+			template: "name: {{ .name }}\n{{ include \"image\" .Values }}\n",
+			data:     map[string]any{"name": "app", "Values": map[string]any{"image": nil}},
+			expected: types.TemplateError{
+				TemplatePath: "shared.tmpl",
+				Line:         1,
+				Column:       37,
+				Expression:   ".image.tag",
+				DataPath:     ".Values.image.tag",
+				Hint:         ".Values.image is nil",
+				Message:      "nil pointer evaluating interface {}.tag",
+				Snippet:      []types.SourceLine{{Number: 1, Text: shared}},
+				Stack:        []types.TemplateFrame{{TemplatePath: "main", Line: 2, Column: 4, Expression: `include "image" .Values`}},
+			},
+		},
+		{
+			name:     "missing key in strict mode",
+			template: "a\nb\nc: {{ .Values.missing.x }}\nd\ne\nf\n",
+			data:     map[string]any{"Values": map[string]any{}},
+			strict:   true,
+			expected: types.TemplateError{
+				TemplatePath: "main",
+				Line:         3,
+				Column:       14,
+				Expression:   ".Values.missing.x",
+				DataPath:     ".Values.missing.x",
+				Hint:         ".Values.missing is missing",
+				Message:      `map has no entry for key "missing"`,
+				Snippet: []types.SourceLine{
+					{Number: 1, Text: "a"},
+					{Number: 2, Text: "b"},
+					{Number: 3, Text: "c: {{ .Values.missing.x }}"},
+					{Number: 4, Text: "d"},
+					{Number: 5, Text: "e"},
+				},
+			},
+		},
+		{
+			name:     "tpl with variable data",
+			template: `{{ $v := .Values }}{{ tpl "x\n{{ .q.r }}" $v }}`,
+			data:     map[string]any{"Values": map[string]any{"q": nil}},
+			expected: types.TemplateError{
+				TemplatePath: tplSourceName,
+				Line:         2,
+				Column:       6,
+				Expression:   ".q.r",
+				Message:      "nil pointer evaluating interface {}.r",
+				Snippet:      []types.SourceLine{{Number: 1, Text: "x"}, {Number: 2, Text: "{{ .q.r }}"}},
+				Stack:        []types.TemplateFrame{{TemplatePath: "main", Line: 1, Column: 23, Expression: `tpl "x\n{{ .q.r }}" $v`}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sharedInput := NewInput("shared.tmpl", true, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(shared)))
+			item := NewInput("main", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(tt.template)))
+			tmpl, err := InitTemplate([]*Input{sharedInput}, tt.strict, false)
+			require.NoError(t, err)
+			tmpl, err = ParseTemplateItems(tmpl, []*Input{item}, "")
+			require.NoError(t, err)
+			ts := &TemplateSet{Template: tmpl, sources: templateSources([]*Input{sharedInput}, []*Input{item})}
+
+			err = ts.Template.ExecuteTemplate(&bytes.Buffer{}, "main", tt.data)
+			require.Error(t, err)
+			te := ts.Diagnose(err, "main")
+			tt.expected.Err = err
+			assert.Equal(t, &tt.expected, te)
+		})
+	}
+}
+
+func TestParseTemplateItems_ParseError(t *testing.T) {
+	item := NewInput("bad", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte("ok\n{{ .a | nope }}\n")))
+	_, err := ParseTemplateItems(newTestTemplate(t), []*Input{item}, "")
+	require.Error(t, err)
+	var te *types.TemplateError
+	require.ErrorAs(t, err, &te)
+	assert.Equal(t, "bad", te.TemplatePath)
+	assert.Equal(t, 2, te.Line)
+	assert.Equal(t, `function "nope" not defined`, te.Message)
+	assert.Equal(t, []types.SourceLine{{Number: 1, Text: "ok"}, {Number: 2, Text: "{{ .a | nope }}"}}, te.Snippet)
+}
+
+func newTestTemplate(t *testing.T) *template.Template {
+	t.Helper()
+	tmpl, err := InitTemplate(nil, false, false)
+	require.NoError(t, err)
+	return tmpl
+}
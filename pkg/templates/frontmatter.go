@@ -0,0 +1,181 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// frontMatterMarker is the first line of a front matter block. A plain --- line is template text, such as the start
+// of a YAML document, whatever its keys are.
+const frontMatterMarker = "--- # yutc"
+
+// frontMatterDelimiter is the line a front matter block ends with.
+const frontMatterDelimiter = "---"
+
+// FrontMatter is the YAML block, between a "--- # yutc" and a --- line, a template file may start with to control
+// its output. It is stripped from the template before parsing.
+type FrontMatter struct {
+	// Output is the path of the output file relative to the output directory, rendered as a template
+	Output string `yaml:"output"`
+	// Mode is the permissions of the output file, 0 to keep the default
+	Mode FileMode `yaml:"mode"`
+	// Skip is rendered as a template and the file is not rendered if it is true
+	Skip string `yaml:"skip"`
+	// Data holds defaults for the data the template is rendered with
+	Data map[string]any `yaml:"data"`
+	// Overwrite overrides --overwrite for the output file if set
+	Overwrite *bool `yaml:"overwrite"`
+}
+
+// FileMode is a file mode written in octal, ex: 0755 or "0755".
+type FileMode os.FileMode
+
+// UnmarshalYAML parses the mode as octal, whether or not it starts with 0.
+func (m *FileMode) UnmarshalYAML(b []byte) error {
+	mode, err := ParseFileMode(strings.Trim(strings.TrimSpace(string(b)), `"'`))
+	if err != nil {
+		return err
+	}
+	*m = mode
+	return nil
+}
+
+// ParseFileMode parses octal permissions, ex: 0755, 755 or 0o755.
+func ParseFileMode(s string) (FileMode, error) {
+	mode, err := strconv.ParseUint(strings.TrimPrefix(s, "0o"), 8, 32)
+	if err != nil || mode > 0o7777 {
+		return 0, fmt.Errorf("invalid mode %s: must be octal permissions, ex: 0755", s)
+	}
+	return FileMode(mode), nil
+}
+
+// WithDefaults returns data with the values of the front matter data it does not set, merging maps recursively.
+func (fm *FrontMatter) WithDefaults(data map[string]any) map[string]any {
+	if fm == nil || len(fm.Data) == 0 {
+		return data
+	}
+	return withDefaults(data, fm.Data)
+}
+
+func withDefaults(data, defaults map[string]any) map[string]any {
+	out := make(map[string]any, len(data)+len(defaults))
+	maps.Copy(out, data)
+	for key, def := range defaults {
+		value, ok := out[key]
+		if !ok {
+			out[key] = def
+			continue
+		}
+		valueMap, isMap := value.(map[string]any)
+		defMap, defIsMap := def.(map[string]any)
+		if isMap && defIsMap {
+			out[key] = withDefaults(valueMap, defMap)
+		}
+	}
+	return out
+}
+
+// has reports whether the front matter data sets path, made of keys and schema.ItemSegment.
+func (fm *FrontMatter) has(path []string) bool {
+	if fm == nil {
+		return false
+	}
+	var v any = fm.Data
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			// the elements of lists are not checked
+			_, isList := v.([]any)
+			return isList
+		}
+		if v, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFrontMatter splits the front matter from the start of text and parses it. text is all body if it does not
+// start with a "--- # yutc" line.
+func parseFrontMatter(text string) (fm *FrontMatter, frontMatter, body string, err error) {
+	frontMatter, body, marked := splitFrontMatter(text)
+	if !marked {
+		return nil, "", text, nil
+	}
+	if frontMatter == "" {
+		return nil, "", text, fmt.Errorf("invalid front matter: no %s line after %s", frontMatterDelimiter, frontMatterMarker)
+	}
+	block := frontMatter[strings.Index(frontMatter, "\n")+1:]
+	block = block[:strings.LastIndex(block, frontMatterDelimiter)]
+	fm = &FrontMatter{}
+	if err = yaml.UnmarshalWithOptions([]byte(block), fm, yaml.DisallowUnknownField()); err != nil {
+		return nil, "", text, fmt.Errorf("invalid front matter: %w", err)
+	}
+	return fm, frontMatter, body, nil
+}
+
+// splitFrontMatter returns the lines of text from a first "--- # yutc" line to the next --- line, included, and the
+// rest. marked is true if text starts with "--- # yutc", and frontMatter is "" if it does not or the block is not
+// closed.
+func splitFrontMatter(text string) (frontMatter, body string, marked bool) {
+	lines := strings.SplitAfter(text, "\n")
+	if strings.Join(strings.Fields(lines[0]), " ") != frontMatterMarker {
+		return "", text, false
+	}
+	size := len(lines[0])
+	for _, line := range lines[1:] {
+		size += len(line)
+		if strings.TrimRight(line, "\r\n") == frontMatterDelimiter {
+			return text[:size], text[size:], true
+		}
+	}
+	return "", text, true
+}
+
+// RenderFrontMatter renders the output path and skip condition of the front matter of item with data. output is
+// "" if the front matter does not set one, and is not rendered if the file is skipped.
+func (ts *TemplateSet) RenderFrontMatter(item *Input, data map[string]any) (output string, skip bool, err error) {
+	fm := item.Template.FrontMatter
+	if fm == nil {
+		return "", false, nil
+	}
+	if fm.Skip != "" {
+		rendered, err := ts.renderFrontMatterValue(item, "skip", fm.Skip, data)
+		if err != nil {
+			return "", false, err
+		}
+		if rendered = strings.TrimSpace(rendered); rendered != "" {
+			if skip, err = strconv.ParseBool(rendered); err != nil {
+				return "", false, fmt.Errorf("front matter skip of %s must render as true or false, got %q", item.Name, rendered)
+			}
+		}
+		if skip {
+			return "", true, nil
+		}
+	}
+	if fm.Output != "" {
+		if output, err = ts.renderFrontMatterValue(item, "output", fm.Output, data); err != nil {
+			return "", false, err
+		}
+	}
+	return strings.TrimSpace(output), false, nil
+}
+
+func (ts *TemplateSet) renderFrontMatterValue(item *Input, key, text string, data map[string]any) (string, error) {
+	name := item.Template.NewName + "#" + key
+	t, err := ts.Template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse front matter %s of %s: %w", key, item.Name, err)
+	}
+	var out bytes.Buffer
+	if err = t.ExecuteTemplate(&out, name, data); err != nil {
+		return "", fmt.Errorf("unable to render front matter %s of %s: %w", key, item.Name, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,180 @@
+package templates
+
+import (
+	"io"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	overwrite := false
+	tests := []struct {
+		name        string
+		text        string
+		expected    *FrontMatter
+		frontMatter string
+		body        string
+		expectedErr string
+	}{
+		{
+			name: "no front matter",
+			text: "a: {{ .a }}\n",
+			body: "a: {{ .a }}\n",
+		},
+		{
+			name:        "all keys",
+			text:        "--- # yutc\noutput: '{{ .name }}.sh'\nmode: 0755\nskip: '{{ not .enabled }}'\ndata:\n  a: 1\noverwrite: false\n---\necho {{ .a }}\n",
+			expected:    &FrontMatter{Output: "{{ .name }}.sh", Mode: 0o755, Skip: "{{ not .enabled }}", Data: map[string]any{"a": uint64(1)}, Overwrite: &overwrite},
+			frontMatter: "--- # yutc\noutput: '{{ .name }}.sh'\nmode: 0755\nskip: '{{ not .enabled }}'\ndata:\n  a: 1\noverwrite: false\n---\n",
+			body:        "echo {{ .a }}\n",
+		},
+		{
+			name:        "quoted mode without leading zero and crlf",
+			text:        "--- # yutc\r\nmode: \"600\"\r\n---\r\nbody",
+			expected:    &FrontMatter{Mode: 0o600},
+			frontMatter: "--- # yutc\r\nmode: \"600\"\r\n---\r\n",
+			body:        "body",
+		},
+		{
+			name: "yaml document separators",
+			text: "---\napiVersion: v1\n---\nkind: {{ .kind }}\n",
+			body: "---\napiVersion: v1\n---\nkind: {{ .kind }}\n",
+		},
+		{
+			name: "plain delimiter",
+			text: "---\noutput: a.txt\n---\nbody",
+			body: "---\noutput: a.txt\n---\nbody",
+		},
+		{
+			name: "yaml document with a front matter key",
+			text: "---\ndata: foo\n---\nkind: {{ .kind }}\n",
+			body: "---\ndata: foo\n---\nkind: {{ .kind }}\n",
+		},
+		{
+			name: "yaml document with front matter keys and a comment",
+			text: "--- # values\ndata:\n  key: v\n---\nkind: {{ .kind }}\n",
+			body: "--- # values\ndata:\n  key: v\n---\nkind: {{ .kind }}\n",
+		},
+		{
+			name: "unclosed plain delimiter",
+			text: "---\noutput: a\n",
+			body: "---\noutput: a\n",
+		},
+		{
+			name: "plain delimiter with an invalid mode",
+			text: "---\nmode: rwx\n---\n",
+			body: "---\nmode: rwx\n---\n",
+		},
+		{
+			name:        "unclosed",
+			text:        "--- # yutc\noutput: a\n",
+			expectedErr: "no --- line after --- # yutc",
+		},
+		{
+			name:        "unknown key",
+			text:        "--- # yutc\noutput: a\nouput: b\n---\n",
+			expectedErr: "unknown field \"ouput\"",
+		},
+		{
+			name:        "invalid mode",
+			text:        "--- # yutc\nmode: rwx\n---\n",
+			expectedErr: "invalid mode rwx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, frontMatter, body, err := parseFrontMatter(tt.text)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fm)
+			assert.Equal(t, tt.frontMatter, frontMatter)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+}
+
+func TestFrontMatter_WithDefaults(t *testing.T) {
+	fm := &FrontMatter{Data: map[string]any{"a": 1, "nested": map[string]any{"b": 2, "c": 3}}}
+	data := map[string]any{"a": 10, "nested": map[string]any{"b": 20}}
+	assert.Equal(t, map[string]any{"a": 10, "nested": map[string]any{"b": 20, "c": 3}}, fm.WithDefaults(data))
+	assert.Equal(t, map[string]any{"a": 10, "nested": map[string]any{"b": 20}}, data, "the data must not be changed")
+
+	var none *FrontMatter
+	assert.Equal(t, data, none.WithDefaults(data))
+}
+
+func TestTemplateSet_RenderFrontMatter(t *testing.T) {
+	tests := []struct {
+		name           string
+		template       string
+		data           map[string]any
+		expectedOutput string
+		expectedSkip   bool
+		expectedErr    string
+	}{
+		{
+			name:     "no front matter",
+			template: "x",
+		},
+		{
+			name:           "templated output",
+			template:       "--- # yutc\noutput: '{{ .name | lower }}/app.yaml'\nskip: '{{ not .enabled }}'\n---\nx",
+			data:           map[string]any{"name": "API", "enabled": true},
+			expectedOutput: "api/app.yaml",
+		},
+		{
+			name:         "skipped",
+			template:     "--- # yutc\noutput: '{{ .missing.key }}'\nskip: '{{ not .enabled }}'\n---\nx",
+			data:         map[string]any{"enabled": false},
+			expectedSkip: true,
+		},
+		{
+			name:         "skip from yaml bool",
+			template:     "--- # yutc\nskip: true\n---\nx",
+			expectedSkip: true,
+		},
+		{
+			name:        "skip not a bool",
+			template:    "--- # yutc\nskip: '{{ .enabled }}'\n---\nx",
+			data:        map[string]any{"enabled": "yes"},
+			expectedErr: `front matter skip of main must render as true or false, got "yes"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			item := NewInput("main", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(tt.template)))
+			ts, err := LoadTemplateSet([]*Input{item}, nil, tt.data, false, false, "", false, &logger)
+			require.NoError(t, err)
+			output, skip, err := ts.RenderFrontMatter(item, tt.data)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+			assert.Equal(t, tt.expectedSkip, skip)
+		})
+	}
+}
+
+func TestFrontMatter_ErrorLines(t *testing.T) {
+	logger := zerolog.Nop()
+	item := NewInput("main", false, loader.WithSource(loader.SourceKindFile),
+		loader.WithContentBytes([]byte("--- # yutc\nmode: 0644\n---\na: 1\nb: {{ .a.b }}\n")))
+	ts, err := LoadTemplateSet([]*Input{item}, nil, nil, true, false, "", false, &logger)
+	require.NoError(t, err)
+	err = ts.Template.ExecuteTemplate(io.Discard, "main", map[string]any{})
+	require.Error(t, err)
+	te := ts.Diagnose(err, "main")
+	assert.Equal(t, 5, te.Line, "lines are counted from the start of the file")
+	assert.Equal(t, []types.SourceLine{{Number: 3, Text: "---"}, {Number: 4, Text: "a: 1"}, {Number: 5, Text: "b: {{ .a.b }}"}}, te.Snippet)
+}
@@ -0,0 +1,84 @@
+package templates
+
+import (
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+)
+
+// IgnoreFileName is the file in the root of a template directory or archive listing, with the syntax of
+// .gitignore, the files that are left out of it.
+const IgnoreFileName = ".yutcignore"
+
+// ignoreRule is a pattern of an ignore file.
+type ignoreRule struct {
+	pattern string
+	negate  bool // the pattern started with !, files it matches are included again
+	dirOnly bool // the pattern ended with /, it only matches directories
+}
+
+// parseIgnoreFile returns the rules of the lines of an ignore file, skipping blank lines and # comments.
+func parseIgnoreFile(content string) ([]ignoreRule, error) {
+	var rules []ignoreRule
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		rule.pattern, rule.negate = strings.CutPrefix(line, "!")
+		rule.pattern = strings.TrimPrefix(rule.pattern, `\`)
+		rule.pattern, rule.dirOnly = strings.CutSuffix(rule.pattern, "/")
+		if err := loader.ValidateGlob(rule.pattern); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ignored reports whether the slash separated path rel is ignored by rules: the last rule matching it wins.
+func ignored(rules []ignoreRule, rel string, isDir bool) bool {
+	ignore := false
+	for _, rule := range rules {
+		if rule.matches(rel, isDir) {
+			ignore = !rule.negate
+		}
+	}
+	return ignore
+}
+
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if !r.dirOnly || isDir {
+		return loader.MatchGlob(r.pattern, rel)
+	}
+	// a file can only be matched through the directories it is in
+	dir := path.Dir(rel)
+	return dir != "." && loader.MatchGlob(r.pattern, dir)
+}
+
+// excludes reports whether the file or directory at the slash separated path rel of the container ti is left
+// out of it, by its exclude or include globs, its ignore file, or as the ignore file itself or part of a .git
+// directory.
+func (ti *Input) excludes(rel string, isDir bool) bool {
+	if rel == IgnoreFileName || slices.Contains(strings.Split(rel, "/"), ".git") {
+		return true
+	}
+	if loader.MatchAnyGlob(ti.Container.Exclude, rel) || ignored(ti.Container.ignore, rel, isDir) {
+		return true
+	}
+	return !isDir && len(ti.Container.Include) > 0 && !loader.MatchAnyGlob(ti.Container.Include, rel)
+}
+
+// IsPartial reports whether ti is a partial of a container: a file named _*.tpl, as Helm's _helpers.tpl. Partials
+// are parsed with the common templates for their defines, but not rendered.
+func (ti *Input) IsPartial() bool {
+	if ti.Container.Root == nil || ti.Container.Root == ti {
+		return false
+	}
+	name := path.Base(filepath.ToSlash(ti.Name))
+	return strings.HasPrefix(name, "_") && path.Ext(name) == ".tpl"
+}
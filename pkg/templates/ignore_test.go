@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnored(t *testing.T) {
+	rules, err := parseIgnoreFile("# editor files\n*.swp\n\nbuild/\ndocs/*\n!docs/keep.md\n\\#notes\n")
+	require.NoError(t, err)
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{rel: "a.yaml.swp", want: true},
+		{rel: "nested/.a.yaml.swp", want: true},
+		{rel: "build", isDir: true, want: true},
+		{rel: "build/out.yaml", want: true},
+		{rel: "build", want: false},
+		{rel: "docs/index.md", want: true},
+		{rel: "docs/keep.md", want: false},
+		{rel: "#notes", want: true},
+		{rel: "app.yaml.tmpl", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.want, ignored(rules, tt.rel, tt.isDir))
+		})
+	}
+
+	_, err = parseIgnoreFile("[a\n")
+	assert.ErrorContains(t, err, `invalid glob "[a"`)
+}
+
+func TestInput_excludes(t *testing.T) {
+	root := NewInput("root", false)
+	root.Container.Include = []string{"*.tmpl", "*.tpl"}
+	root.Container.Exclude = []string{"skip"}
+	assert.True(t, root.excludes(IgnoreFileName, false))
+	assert.True(t, root.excludes(".git/config", false))
+	assert.True(t, root.excludes("skip/a.tmpl", false))
+	assert.True(t, root.excludes("README.md", false))
+	assert.False(t, root.excludes("docs", true), "directories are kept for the files they hold")
+	assert.False(t, root.excludes("docs/a.tmpl", false))
+}
+
+func TestInput_IsPartial(t *testing.T) {
+	root := NewInput("root", false)
+	for name, want := range map[string]bool{
+		"root/templates/_helpers.tpl": true,
+		"root/_helpers.tmpl":          false,
+		"root/pkg/__init__.py":        false,
+		"root/helpers.tpl":            false,
+	} {
+		child := NewInput(name, false)
+		child.Container.Root = root
+		assert.Equal(t, want, child.IsPartial(), name)
+	}
+	assert.False(t, NewInput("_helpers.tpl", false).IsPartial(), "files given directly are rendered")
+}
@@ -0,0 +1,286 @@
+package templates
+
+import (
+	"maps"
+	"slices"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/adam-huganir/yutc/pkg/schema"
+)
+
+// InferSchema walks the parsed templates of the set, following field chains through index, with, range,
+// variables and template/include calls, and returns a skeleton JSON Schema of the data they use.
+func InferSchema(ts *TemplateSet) *schema.Inferred {
+	root := schema.NewUsage()
+	w := newUsageWalker(ts.Template, root, nil)
+	for _, item := range ts.TemplateFiles {
+		w.walkTemplate(item.Template.NewName, root, root)
+	}
+	return schema.Infer(root)
+}
+
+// templateCall identifies a template walked with a given dot, to stop recursive templates.
+type templateCall struct {
+	name string
+	dot  *schema.Usage
+}
+
+// usageWalker records the data used by template parse trees. A nil usage is a value that doesn't come
+// from the data (ex: the result of most functions), so nothing is recorded for it.
+type usageWalker struct {
+	template *template.Template
+	visiting map[templateCall]bool
+
+	// onData, if set, is called for each field chain and index that reads the data with the tree it is in and
+	// the path of the value from the root of the data, made of keys and schema.ItemSegment
+	onData func(tree *parse.Tree, node parse.Node, path []string)
+	paths  map[*schema.Usage][]string // the paths of usages, tracked when onData is set
+	tree   *parse.Tree                // the tree being walked
+}
+
+// newUsageWalker returns a walker of the templates of t. onData may be nil.
+func newUsageWalker(t *template.Template, root *schema.Usage, onData func(*parse.Tree, parse.Node, []string)) *usageWalker {
+	w := &usageWalker{
+		template: t,
+		visiting: make(map[templateCall]bool),
+		onData:   onData,
+	}
+	if onData != nil {
+		w.paths = map[*schema.Usage][]string{root: {}}
+	}
+	return w
+}
+
+// scope is the dot and the variables visible at a point in a template.
+type scope struct {
+	dot  *schema.Usage
+	vars map[string]*schema.Usage
+}
+
+func (s *scope) with(dot *schema.Usage) *scope {
+	return &scope{dot: dot, vars: s.vars}
+}
+
+func (w *usageWalker) walkTemplate(name string, dot, root *schema.Usage) {
+	t := w.template.Lookup(name)
+	if t == nil || t.Tree == nil || t.Tree.Root == nil {
+		return
+	}
+	call := templateCall{name: name, dot: dot}
+	if w.visiting[call] {
+		return
+	}
+	w.visiting[call] = true
+	defer delete(w.visiting, call)
+	outer := w.tree
+	w.tree = t.Tree
+	defer func() { w.tree = outer }()
+	w.walk(t.Tree.Root, &scope{dot: dot, vars: map[string]*schema.Usage{"$": root}})
+}
+
+func (w *usageWalker) walk(node parse.Node, s *scope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		// variables declared in a list are visible until its end
+		inner := &scope{dot: s.dot, vars: maps.Clone(s.vars)}
+		for _, child := range n.Nodes {
+			w.walk(child, inner)
+		}
+	case *parse.ActionNode:
+		w.pipe(n.Pipe, s)
+	case *parse.IfNode:
+		w.pipe(n.Pipe, s)
+		w.walk(n.List, s)
+		w.walk(n.ElseList, s)
+	case *parse.WithNode:
+		dot := w.pipe(n.Pipe, s)
+		w.walk(n.List, s.with(dot))
+		w.walk(n.ElseList, s)
+	case *parse.RangeNode:
+		ranged := w.pipeResult(n.Pipe, s)
+		var item *schema.Usage
+		if ranged != nil {
+			ranged.Ranged = true
+			item = w.child(ranged, schema.ItemSegment)
+		}
+		body := &scope{dot: item, vars: maps.Clone(s.vars)}
+		if decl := n.Pipe.Decl; len(decl) > 0 {
+			body.vars[decl[len(decl)-1].Ident[0]] = item
+			if len(decl) == 2 {
+				body.vars[decl[0].Ident[0]] = nil // the key or index
+			}
+		}
+		w.walk(n.List, body)
+		w.walk(n.ElseList, s)
+	case *parse.TemplateNode:
+		var dot *schema.Usage
+		if n.Pipe != nil {
+			dot = w.pipe(n.Pipe, s)
+		}
+		if dot != nil {
+			w.walkTemplate(n.Name, dot, s.vars["$"])
+		}
+	}
+}
+
+// pipe records the data used by a pipeline and returns the usage of its result. Declared variables are
+// added to the scope.
+func (w *usageWalker) pipe(p *parse.PipeNode, s *scope) *schema.Usage {
+	result := w.pipeResult(p, s)
+	if p != nil && len(p.Decl) == 1 {
+		s.vars[p.Decl[0].Ident[0]] = result
+	}
+	return result
+}
+
+// pipeResult records the data used by a pipeline and returns the usage of its result.
+func (w *usageWalker) pipeResult(p *parse.PipeNode, s *scope) *schema.Usage {
+	if p == nil {
+		return nil
+	}
+	var result *schema.Usage
+	for i, cmd := range p.Cmds {
+		var piped *schema.Usage
+		if i > 0 {
+			piped = result
+		}
+		result = w.command(cmd, s, piped, i > 0)
+	}
+	return result
+}
+
+// command records the data used by a command and returns the usage of its result. piped is the result
+// of the previous command in the pipeline, passed as the final argument when isPiped.
+func (w *usageWalker) command(cmd *parse.CommandNode, s *scope, piped *schema.Usage, isPiped bool) *schema.Usage {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		args := cmd.Args[1:]
+		switch ident.Ident {
+		case "index":
+			var target *schema.Usage
+			if len(args) > 0 {
+				target = w.arg(args[0], s)
+				args = args[1:]
+			} else if isPiped {
+				target = piped
+			}
+			for _, key := range args {
+				w.arg(key, s)
+				target = w.index(target, key)
+			}
+			if len(args) > 0 {
+				w.used(cmd, target)
+			}
+			return target
+		case "include":
+			if len(args) == 2 {
+				if name, ok := args[0].(*parse.StringNode); ok {
+					if dot := w.arg(args[1], s); dot != nil {
+						w.walkTemplate(name.Text, dot, s.vars["$"])
+					}
+				}
+			} else if len(args) == 1 && isPiped && piped != nil {
+				if name, ok := args[0].(*parse.StringNode); ok {
+					w.walkTemplate(name.Text, piped, s.vars["$"])
+				}
+			}
+			return nil
+		case "default", "required", "coalesce", "ternary":
+			// these return one of their arguments, which are most likely data
+			var result *schema.Usage
+			for _, arg := range args {
+				if usage := w.arg(arg, s); usage != nil {
+					result = usage
+				}
+			}
+			if isPiped && piped != nil {
+				result = piped
+			}
+			return result
+		}
+		for _, arg := range args {
+			w.arg(arg, s)
+		}
+		return nil
+	}
+	for _, arg := range cmd.Args[1:] {
+		w.arg(arg, s)
+	}
+	return w.arg(cmd.Args[0], s)
+}
+
+// arg records the data used by an argument and returns the usage of its value.
+func (w *usageWalker) arg(node parse.Node, s *scope) *schema.Usage {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return s.dot
+	case *parse.FieldNode:
+		return w.used(n, w.fields(s.dot, n.Ident))
+	case *parse.VariableNode:
+		if len(n.Ident) == 1 {
+			return s.vars[n.Ident[0]]
+		}
+		return w.used(n, w.fields(s.vars[n.Ident[0]], n.Ident[1:]))
+	case *parse.ChainNode:
+		return w.used(n, w.fields(w.arg(n.Node, s), n.Field))
+	case *parse.PipeNode:
+		return w.pipe(n, &scope{dot: s.dot, vars: s.vars})
+	}
+	return nil
+}
+
+// used calls onData for a node that reads the data u and returns u.
+func (w *usageWalker) used(node parse.Node, u *schema.Usage) *schema.Usage {
+	if w.onData != nil && u != nil {
+		if path, ok := w.paths[u]; ok {
+			w.onData(w.tree, node, path)
+		}
+	}
+	return u
+}
+
+// child returns the usage of a field or the elements of u, tracking its path.
+func (w *usageWalker) child(u *schema.Usage, segment string) *schema.Usage {
+	var c *schema.Usage
+	if segment == schema.ItemSegment {
+		c = u.Item()
+	} else {
+		c = u.Field(segment)
+	}
+	if w.paths != nil {
+		if path, ok := w.paths[u]; ok {
+			if _, tracked := w.paths[c]; !tracked {
+				w.paths[c] = append(slices.Clone(path), segment)
+			}
+		}
+	}
+	return c
+}
+
+func (w *usageWalker) fields(u *schema.Usage, fields []string) *schema.Usage {
+	for _, field := range fields {
+		if u == nil {
+			return nil
+		}
+		u = w.child(u, field)
+	}
+	return u
+}
+
+// index returns the usage of an index expression's key: a field for a string, an element for a
+// number, and the elements (of a list or object) for anything else.
+func (w *usageWalker) index(u *schema.Usage, key parse.Node) *schema.Usage {
+	if u == nil {
+		return nil
+	}
+	if k, ok := key.(*parse.StringNode); ok {
+		return w.child(u, k.Text)
+	}
+	return w.child(u, schema.ItemSegment)
+}
@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		common   string
+		expected string
+	}{
+		{
+			name:     "field chains",
+			template: `{{ .Values.image.repository }}:{{ .Values.image.tag | default "latest" }}`,
+			expected: `{"Values": {"type": "object", "properties": {"image": {"type": "object", "properties": {"repository": true, "tag": true}}}}}`,
+		},
+		{
+			name:     "with scope and root variable",
+			template: `{{ with .svc }}{{ .port }}{{ $.name }}{{ else }}{{ .fallback }}{{ end }}`,
+			expected: `{"svc": {"type": "object", "properties": {"port": true}}, "name": true, "fallback": true}`,
+		},
+		{
+			name:     "range with variables",
+			template: `{{ range $i, $c := .containers }}{{ $c.name }}{{ $i }}{{ end }}`,
+			expected: `{"containers": {"type": ["array", "object"], "items": {"type": "object", "properties": {"name": true}}, "additionalProperties": {"type": "object", "properties": {"name": true}}}}`,
+		},
+		{
+			name:     "assigned variable",
+			template: `{{ $img := .image }}{{ $img.tag }}`,
+			expected: `{"image": {"type": "object", "properties": {"tag": true}}}`,
+		},
+		{
+			name:     "index by name and number",
+			template: `{{ index .labels "app" }}{{ index .ports 0 }}`,
+			expected: `{"labels": {"type": "object", "properties": {"app": true}}, "ports": {"type": ["array", "object"], "items": true, "additionalProperties": true}}`,
+		},
+		{
+			name:     "template and include calls",
+			template: `{{ template "name" .app }}{{ include "name" .other }}`,
+			common:   `{{ define "name" }}{{ .name }}{{ end }}`,
+			expected: `{"app": {"type": "object", "properties": {"name": true}}, "other": {"type": "object", "properties": {"name": true}}}`,
+		},
+		{
+			name:     "recursive template",
+			template: `{{ define "tree" }}{{ .name }}{{ template "tree" . }}{{ end }}{{ template "tree" .root }}`,
+			expected: `{"root": {"type": "object", "properties": {"name": true}}}`,
+		},
+		{
+			name:     "function results are not data",
+			template: `{{ (list 1 2).foo }}{{ upper .title }}`,
+			expected: `{"title": true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			tmplFile := filepath.Join(tmpDir, "test.tmpl")
+			require.NoError(t, os.WriteFile(tmplFile, []byte(tt.template), 0o644))
+			var common []*Input
+			if tt.common != "" {
+				commonFile := filepath.Join(tmpDir, "_helpers.tmpl")
+				require.NoError(t, os.WriteFile(commonFile, []byte(tt.common), 0o644))
+				common = append(common, NewInput(commonFile, true))
+			}
+			logger := zerolog.Nop()
+
+			ts, err := LoadTemplateSet([]*Input{NewInput(tmplFile, false)}, common, nil, false, false, "", false, &logger)
+			require.NoError(t, err)
+
+			actual, err := json.Marshal(InferSchema(ts))
+			require.NoError(t, err)
+			expected := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object", "properties": ` + tt.expected + `}`
+			assert.JSONEq(t, expected, string(actual))
+		})
+	}
+}
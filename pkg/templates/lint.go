@@ -0,0 +1,291 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+
+	"github.com/adam-huganir/yutc/pkg/lint"
+	"github.com/adam-huganir/yutc/pkg/schema"
+)
+
+// LintOptions configures the checks of Lint.
+type LintOptions struct {
+	AllowShell bool // the shell function may be used, as with --allow-shell
+	// HasData reports whether a path of the data, made of keys and schema.ItemSegment, is in the merged data or
+	// declared by a schema. Data keys are not checked if it is nil.
+	HasData func(path []string) bool
+}
+
+// Lint checks the parsed templates of the set without executing them and returns the problems found, ordered
+// by location. The set must be parsed with the shell function, so templates using it can be checked.
+func Lint(ts *TemplateSet, opts LintOptions) []lint.Finding {
+	l := &linter{ts: ts, opts: opts, seen: make(map[string]bool)}
+	l.checkTemplates()
+	l.checkData()
+	l.checkWhitespace()
+	lint.Sort(l.findings)
+	return l.findings
+}
+
+type linter struct {
+	ts       *TemplateSet
+	opts     LintOptions
+	findings []lint.Finding
+	seen     map[string]bool // findings already reported, as the same node can be walked more than once
+}
+
+// report adds a finding at pos in tree, once.
+func (l *linter) report(rule lint.Rule, tree *parse.Tree, pos parse.Pos, format string, args ...any) {
+	path, line, column := l.locate(tree, pos)
+	l.add(lint.Finding{Rule: rule, Path: path, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) add(f lint.Finding) {
+	key := fmt.Sprintf("%s:%d:%d:%s:%s", f.Path, f.Line, f.Column, f.Rule.ID, f.Message)
+	if l.seen[key] {
+		return
+	}
+	l.seen[key] = true
+	l.findings = append(l.findings, f)
+}
+
+// locate returns the path of the template file of tree and the line and column, from 1, of pos in it.
+func (l *linter) locate(tree *parse.Tree, pos parse.Pos) (string, int, int) {
+	source, ok := l.ts.sources[tree.ParseName]
+	if !ok {
+		return tree.ParseName, 0, 0
+	}
+	if int(pos) > len(source.text) {
+		return source.path, 0, 0
+	}
+	before := source.text[:pos]
+	return source.path, source.lineOffset() + strings.Count(before, "\n") + 1, int(pos) - strings.LastIndex(before, "\n")
+}
+
+// checkTemplates checks the calls to templates, the use of shell and whitespace trimming in every tree.
+func (l *linter) checkTemplates() {
+	used := make(map[string]bool)
+	dynamicInclude := false
+	for _, t := range l.ts.Template.Templates() {
+		tree := t.Tree
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		inspect(tree.Root, func(node parse.Node) {
+			switch n := node.(type) {
+			case *parse.TemplateNode:
+				used[n.Name] = true
+				if l.ts.Template.Lookup(n.Name) == nil {
+					l.report(lint.RuleUndefinedTemplate, tree, n.Pos, "template %q is not defined", n.Name)
+				}
+			case *parse.CommandNode:
+				ident, ok := n.Args[0].(*parse.IdentifierNode)
+				if !ok || ident.Ident != "include" || len(n.Args) < 2 {
+					return
+				}
+				name, ok := n.Args[1].(*parse.StringNode)
+				if !ok {
+					dynamicInclude = true
+					return
+				}
+				used[name.Text] = true
+				if l.ts.Template.Lookup(name.Text) == nil {
+					l.report(lint.RuleUndefinedTemplate, tree, name.Pos, "template %q is not defined", name.Text)
+				}
+			case *parse.IdentifierNode:
+				if n.Ident == "shell" && !l.opts.AllowShell {
+					l.report(lint.RuleShellNotAllowed, tree, n.Pos, "shell is only available with --allow-shell")
+				}
+			case *parse.ListNode:
+				l.checkTrim(tree, n)
+			}
+		})
+	}
+
+	// a define can't be known to be unused when templates are included by computed names
+	if dynamicInclude {
+		return
+	}
+	for _, t := range l.ts.Template.Templates() {
+		if _, isFile := l.ts.sources[t.Name()]; isFile || used[t.Name()] || t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+		pos := t.Tree.Root.Pos
+		if source, ok := l.ts.sources[t.Tree.ParseName]; ok {
+			// the body starts after the define action
+			pos = actionStart(source.text, pos)
+		}
+		l.report(lint.RuleUnusedDefine, t.Tree, pos, "template %q is defined but never used", t.Name())
+	}
+}
+
+// checkTrim reports output actions of list whose trim markers remove the whitespace separating their output
+// from the text next to them, ex: `name: {{- .name }}` renders as "name:value". Actions without output and
+// pipelines ending in nindent, which adds its own newline, are idiomatic uses of trimming.
+func (l *linter) checkTrim(tree *parse.Tree, list *parse.ListNode) {
+	source, ok := l.ts.sources[tree.ParseName]
+	if !ok {
+		return
+	}
+	text := source.text
+	for i, node := range list.Nodes {
+		action, ok := node.(*parse.ActionNode)
+		if !ok || len(action.Pipe.Decl) > 0 || int(action.Pos) > len(text) {
+			continue
+		}
+		if i > 0 && !endsWithNindent(action.Pipe) {
+			if _, afterText := list.Nodes[i-1].(*parse.TextNode); afterText {
+				before := strings.TrimRight(text[:action.Pos], " \t\r\n")
+				if delim, trimmed := strings.CutSuffix(before, "{{-"); trimmed && strings.TrimRight(delim, " \t\r\n") != delim {
+					l.report(lint.RuleTrimJoinsText, tree, parse.Pos(len(delim)),
+						"{{- removes the whitespace between the text before and the output of %s", action.Pipe)
+				}
+			}
+		}
+		if i+1 < len(list.Nodes) {
+			next, beforeText := list.Nodes[i+1].(*parse.TextNode)
+			if !beforeText || int(next.Pos) > len(text) {
+				continue
+			}
+			before := strings.TrimRight(text[:next.Pos], " \t\r\n")
+			if len(before) < int(next.Pos) && strings.HasSuffix(before, "-}}") && strings.TrimRight(before[:len(before)-3], " \t\r\n") != before[:len(before)-3] {
+				l.report(lint.RuleTrimJoinsText, tree, parse.Pos(len(before)-3),
+					"-}} removes the whitespace between the output of %s and the text after", action.Pipe)
+			}
+		}
+	}
+}
+
+func endsWithNindent(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) == 0 {
+		return false
+	}
+	ident, ok := pipe.Cmds[len(pipe.Cmds)-1].Args[0].(*parse.IdentifierNode)
+	return ok && ident.Ident == "nindent"
+}
+
+// checkData reports data keys used by the templates that HasData doesn't know, once per location at the first
+// missing key of a chain.
+func (l *linter) checkData() {
+	if l.opts.HasData == nil {
+		return
+	}
+	root := schema.NewUsage()
+	var item *Input // the template file being walked, whose front matter data is also known
+	w := newUsageWalker(l.ts.Template, root, func(tree *parse.Tree, node parse.Node, path []string) {
+		for i := 1; i <= len(path); i++ {
+			if l.opts.HasData(path[:i]) || item.Template.FrontMatter.has(path[:i]) {
+				continue
+			}
+			missing := formatDataPath(path[:i])
+			if i < len(path) {
+				missing += " (in " + formatDataPath(path) + ")"
+			}
+			l.report(lint.RuleMissingData, tree, chainStart(node), "%s is not in the data or a schema", missing)
+			return
+		}
+	})
+	for _, item = range l.ts.TemplateFiles {
+		w.walkTemplate(item.Template.NewName, root, root)
+	}
+}
+
+// chainStart returns the position of the start of a field chain, as the parser sets it to its second field.
+func chainStart(node parse.Node) parse.Pos {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if len(n.Ident) > 1 {
+			return n.Pos - parse.Pos(len(n.Ident[0])+1)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 {
+			return n.Pos - parse.Pos(len(n.Ident[0]))
+		}
+	}
+	return node.Position()
+}
+
+// formatDataPath formats a data path as a field chain, ex: .items[].name.
+func formatDataPath(path []string) string {
+	var b strings.Builder
+	for _, segment := range path {
+		if segment != schema.ItemSegment {
+			b.WriteString(".")
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// checkWhitespace reports lines of the template files that end with whitespace.
+func (l *linter) checkWhitespace() {
+	for _, source := range l.ts.sources {
+		for i, line := range strings.Split(source.text, "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if trimmed := strings.TrimRight(line, " \t"); trimmed != line {
+				l.add(lint.Finding{
+					Rule:    lint.RuleTrailingWhitespace,
+					Path:    source.path,
+					Line:    source.lineOffset() + i + 1,
+					Column:  len(trimmed) + 1,
+					Message: "trailing whitespace",
+				})
+			}
+		}
+	}
+}
+
+// inspect calls fn for node and every node below it.
+func inspect(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			inspect(child, fn)
+		}
+	case *parse.ActionNode:
+		inspect(n.Pipe, fn)
+	case *parse.IfNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			inspect(n.Pipe, fn)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			inspect(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			inspect(arg, fn)
+		}
+	case *parse.ChainNode:
+		inspect(n.Node, fn)
+	}
+}
+
+func inspectBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	if n.Pipe != nil {
+		inspect(n.Pipe, fn)
+	}
+	if n.List != nil {
+		inspect(n.List, fn)
+	}
+	if n.ElseList != nil {
+		inspect(n.ElseList, fn)
+	}
+}
@@ -0,0 +1,108 @@
+package templates
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/adam-huganir/yutc/pkg/schema"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintTemplates(t *testing.T, template string, shared string, opts LintOptions) []string {
+	t.Helper()
+	item := NewInput("main", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(template)))
+	var sharedInputs []*Input
+	if shared != "" {
+		sharedInputs = append(sharedInputs, NewInput("shared", true, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte(shared))))
+	}
+	logger := zerolog.Nop()
+	ts, err := LoadTemplateSet([]*Input{item}, sharedInputs, nil, false, false, "", true, &logger)
+	require.NoError(t, err)
+	var out []string
+	for _, f := range Lint(ts, opts) {
+		out = append(out, fmt.Sprintf("%s:%d:%d: %s: %s", f.Path, f.Line, f.Column, f.Rule.ID, f.Message))
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		shared   string
+		opts     LintOptions
+		expected []string
+	}{
+		{
+			name:     "undefined and unused templates",
+			template: `{{ template "missing" . }}{{ include "other" . }}{{ include "used" . }}`,
+			shared:   "{{ define \"used\" }}{{ end }}\n{{ define \"unused\" }}{{ end }}",
+			expected: []string{
+				`main:1:13: undefined-template: template "missing" is not defined`,
+				`main:1:38: undefined-template: template "other" is not defined`,
+				`shared:2:1: unused-define: template "unused" is defined but never used`,
+			},
+		},
+		{
+			name:     "defines may be included by computed names",
+			template: `{{ include (printf "%s" .name) . }}`,
+			shared:   `{{ define "unused" }}{{ end }}`,
+		},
+		{
+			name:     "shell",
+			template: `{{ shell "echo" | upper }}`,
+			expected: []string{"main:1:4: shell-not-allowed: shell is only available with --allow-shell"},
+		},
+		{
+			name:     "shell allowed",
+			template: `{{ shell "echo" }}`,
+			opts:     LintOptions{AllowShell: true},
+		},
+		{
+			name:     "trimming output into text",
+			template: "a: {{- .a }}\nb:\n{{- .b }}\nc: {{ .c -}}\n  next\n",
+			expected: []string{
+				"main:1:4: trim-joins-text: {{- removes the whitespace between the text before and the output of .a",
+				"main:3:1: trim-joins-text: {{- removes the whitespace between the text before and the output of .b",
+				"main:4:10: trim-joins-text: -}} removes the whitespace between the output of .c and the text after",
+			},
+		},
+		{
+			name: "idiomatic trimming",
+			template: "list:\n{{- range .items }}\n  - {{ . }}\n{{- end }}\n" +
+				"labels:\n  {{- include \"labels\" . | nindent 2 }}\n{{- $x := 1 }}\na{{- .a }}",
+			shared: `{{ define "labels" }}{{ end }}`,
+		},
+		{
+			name:     "trailing whitespace",
+			template: "a: 1  \nb: 2\t\r\nc: 3\n",
+			expected: []string{
+				"main:1:5: trailing-whitespace: trailing whitespace",
+				"main:2:5: trailing-whitespace: trailing whitespace",
+			},
+		},
+		{
+			name:     "data keys",
+			template: "{{ .app.name }}{{ .app.image.tag }}{{ range .app.ports }}{{ .port }}{{ .name }}{{ end }}{{ index .app \"x\" }}",
+			opts: LintOptions{HasData: func(path []string) bool {
+				return slices.Contains([]string{"app", "app.name", "app.ports", "app.ports." + schema.ItemSegment, "app.ports.[].port"},
+					strings.Join(path, "."))
+			}},
+			expected: []string{
+				"main:1:19: missing-data: .app.image (in .app.image.tag) is not in the data or a schema",
+				"main:1:72: missing-data: .app.ports[].name is not in the data or a schema",
+				"main:1:92: missing-data: .app.x is not in the data or a schema",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lintTemplates(t, tt.template, tt.shared, tt.opts))
+		})
+	}
+}
@@ -3,12 +3,14 @@ package templates
 import (
 	"fmt"
 	htmltemplate "html/template"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/adam-huganir/yutc/pkg/quote"
+	"github.com/adam-huganir/yutc/pkg/types"
 	"github.com/rs/zerolog"
 )
 
@@ -16,6 +18,16 @@ import (
 type TemplateSet struct {
 	Template      *template.Template
 	TemplateFiles []*Input
+	CopyFiles     []*Input // files of containers marked to be copied as is, not parsed
+	Partials      []*Input // partials of containers, parsed with the common templates but not rendered
+
+	sources map[string]templateSource // the parsed templates by name, to describe errors with
+}
+
+// Diagnose describes err, from executing the template named templatePath, with the location of the error in
+// the template files, the data being evaluated and the include and tpl calls leading to it.
+func (ts *TemplateSet) Diagnose(err error, templatePath string) *types.TemplateError {
+	return newTemplateError(err, templatePath, ts.sources)
 }
 
 // LoadTemplateSet loads template data and parses them with shared templates and custom functions.
@@ -31,38 +43,58 @@ func LoadTemplateSet(
 ) (*TemplateSet, error) {
 	logger.Debug().Msg("Loading " + strconv.Itoa(len(templateFiles)) + " template(s)")
 
-	t, err := InitTemplate(sharedTemplateBuffers, strict, allowShell)
-	if err != nil {
-		return nil, err
-	}
-
 	// Parse all template data into the same template object
-	var templateItems []*Input
+	var templateItems, copyItems, partialItems []*Input
 	for _, templateFile := range templateFiles {
-		if isDir, err := templateFile.IsDir(); err == nil && !isDir {
-			templateItems = append(templateItems, templateFile)
-		} else if err != nil {
+		// files of a container may also be given on their own, as by yutc new, and are sorted the same way
+		items := []*Input{templateFile}
+		if isContainer, err := templateFile.IsContainer(); err != nil {
 			return nil, err
+		} else if isContainer {
+			items = nil
+			for _, c := range templateFile.AllChildren() {
+				if isDir, err := c.IsDir(); err != nil {
+					return nil, err
+				} else if !isDir {
+					items = append(items, c)
+				}
+			}
 		}
-		children := templateFile.AllChildren()
-		for _, c := range children {
-			if isDir, err := c.IsDir(); err == nil && !isDir {
+		for _, c := range items {
+			switch {
+			case c.IsPartial():
+				partialItems = append(partialItems, c)
+			case c.Template.Copy:
+				copyItems = append(copyItems, c)
+			default:
 				templateItems = append(templateItems, c)
-			} else if err != nil {
-				return nil, err
 			}
 		}
 		logger.Debug().Msgf("Loading from %s template file %s", templateFile.Source, templateFile.Name)
 	}
+	// partials are parsed for their defines, as common templates
+	sharedTemplateBuffers = slices.Concat(sharedTemplateBuffers, partialItems)
+	t, err := InitTemplate(sharedTemplateBuffers, strict, allowShell)
+	if err != nil {
+		return nil, err
+	}
 	if includeFilenames {
 		filenameTemplate, err := InitTemplate(sharedTemplateBuffers, strict, allowShell)
 		if err != nil {
 			return nil, fmt.Errorf("error initializing filename template: %w", err)
 		}
-		err = TemplateFilenames(templateItems, filenameTemplate, mergedData)
+		err = TemplateFilenames(slices.Concat(templateItems, copyItems), filenameTemplate, mergedData)
 		if err != nil {
 			return nil, err
 		}
+		isSkipped := func(item *Input) bool {
+			if item.Template.Skip {
+				logger.Debug().Msgf("Skipping %s as a segment of its name renders empty", item.Name)
+			}
+			return item.Template.Skip
+		}
+		templateItems = slices.DeleteFunc(templateItems, isSkipped)
+		copyItems = slices.DeleteFunc(copyItems, isSkipped)
 	}
 
 	t, err = ParseTemplateItems(t, templateItems, dropExtension)
@@ -72,10 +104,26 @@ func LoadTemplateSet(
 	return &TemplateSet{
 		Template:      t,
 		TemplateFiles: templateItems,
+		CopyFiles:     copyItems,
+		Partials:      partialItems,
+		sources:       templateSources(sharedTemplateBuffers, templateItems),
 	}, nil
 }
 
-// ParseTemplateItems parses template data into the same template object.
+// templateSources maps the names templates are parsed with to their text and path.
+func templateSources(sharedTemplates, items []*Input) map[string]templateSource {
+	sources := make(map[string]templateSource, len(sharedTemplates)+len(items))
+	for idx, shared := range sharedTemplates {
+		sources[sharedTemplateName(idx)] = templateSource{path: shared.Name, text: string(shared.Content.Data)}
+	}
+	for _, item := range items {
+		sources[item.Template.NewName] = itemSource(item)
+	}
+	return sources
+}
+
+// ParseTemplateItems parses template data into the same template object, after stripping and parsing the
+// front matter of each file.
 func ParseTemplateItems(t *template.Template, items []*Input, dropExtension string) (*template.Template, error) {
 	var err error
 	for _, item := range items {
@@ -91,14 +139,26 @@ func ParseTemplateItems(t *template.Template, items []*Input, dropExtension stri
 		}
 		name = strings.TrimSuffix(name, "."+(strings.TrimSpace(strings.TrimPrefix(dropExtension, "."))))
 		item.Template.NewName = name
-		t, err = t.New(name).Parse(string(item.Content.Data))
+		fm, frontMatter, body, err := parseFrontMatter(string(item.Content.Data))
 		if err != nil {
 			return nil, fmt.Errorf("unable to parse template file %s from %s: %w", name, item.Source, err)
 		}
+		item.Template.FrontMatter = fm
+		t, err = t.New(name).Parse(body)
+		if err != nil {
+			sources := map[string]templateSource{name: {path: item.Name, text: body, frontMatter: frontMatter}}
+			return nil, fmt.Errorf("unable to parse template file %s from %s: %w", name, item.Source, newTemplateError(err, item.Name, sources))
+		}
 	}
 	return t, nil
 }
 
+// itemSource returns the source of a template file, its text without the front matter.
+func itemSource(item *Input) templateSource {
+	_, frontMatter, body, _ := parseFrontMatter(string(item.Content.Data))
+	return templateSource{path: item.Name, text: body, frontMatter: frontMatter}
+}
+
 func InitTemplate(sharedTemplates []*Input, strict, allowShell bool) (*template.Template, error) {
 	// Create ONE template for everything (like Helm does)
 	var onError string
@@ -129,7 +189,7 @@ func InitTemplate(sharedTemplates []*Input, strict, allowShell bool) (*template.
 
 	// Parse shared templates
 	for idx, sharedTemplateBuffer := range sharedTemplates {
-		sharedName := "shared-" + strconv.Itoa(idx)
+		sharedName := sharedTemplateName(idx)
 		// It is assumed that shared templates will primarily contain 'define' blocks
 		// which are then referenced by their defined name using 'include'.
 		// The sharedName here is really only for debugging purposes at this time
@@ -141,12 +201,18 @@ func InitTemplate(sharedTemplates []*Input, strict, allowShell bool) (*template.
 		}
 		_, err := t.New(sharedName).Parse(string(sharedTemplateBuffer.Content.Data))
 		if err != nil {
-			return nil, err
+			sources := map[string]templateSource{sharedName: {path: sharedTemplateBuffer.Name, text: string(sharedTemplateBuffer.Content.Data)}}
+			return nil, newTemplateError(err, sharedTemplateBuffer.Name, sources)
 		}
 	}
 	return t, nil
 }
 
+// sharedTemplateName is the name the shared template at idx is parsed with.
+func sharedTemplateName(idx int) string {
+	return "shared-" + strconv.Itoa(idx)
+}
+
 // GetCustomFuncMap returns only the custom yutc functions (no Sprig, no include/tpl).
 func GetCustomFuncMap(ro *RuntimeOptions) template.FuncMap {
 	fm := template.FuncMap{
@@ -2,8 +2,11 @@ package templates
 
 import (
 	"fmt"
+	"slices"
 
 	inputpkg "github.com/adam-huganir/yutc/pkg/input"
+	"github.com/adam-huganir/yutc/pkg/loader"
+	"github.com/theory/jsonpath"
 )
 
 // LoadTemplateInputs loads all Input entries into memory.
@@ -25,8 +28,15 @@ func ParseTemplateArgWithTempDir(arg string, isCommon bool, tempDir string) (*In
 	}
 	argParsed := parsed.Arg
 
-	if argParsed.JSONPath != nil {
-		return nil, fmt.Errorf("key parameter is not supported for template arguments: %s", arg)
+	if argParsed.Format != nil {
+		return nil, fmt.Errorf("format parameter is not supported for template arguments: %s", arg)
+	}
+	if isCommon && (argParsed.JSONPath != nil || argParsed.Out != nil || argParsed.Mode != nil) {
+		return nil, fmt.Errorf("jsonpath, out and mode parameters are not supported for common template arguments: %s", arg)
+	}
+
+	if parsed.SourceType == loader.SourceKindEnv {
+		return nil, fmt.Errorf("env source is not supported for template arguments: %s", arg)
 	}
 
 	ti := NewInput(parsed.EntryName, isCommon, parsed.EntryOpts...)
@@ -38,6 +48,77 @@ func ParseTemplateArgWithTempDir(arg string, isCommon bool, tempDir string) (*In
 	if parsed.Auth != nil {
 		ti.Auth = *parsed.Auth
 	}
+	if argParsed.Include != nil {
+		ti.Container.Include = argParsed.Include.Values
+	}
+	if argParsed.Exclude != nil {
+		ti.Container.Exclude = argParsed.Exclude.Values
+	}
+	for _, glob := range slices.Concat(ti.Container.Include, ti.Container.Exclude) {
+		if err := loader.ValidateGlob(glob); err != nil {
+			return nil, err
+		}
+	}
+	if argParsed.JSONPath != nil {
+		path := argParsed.JSONPath.Value
+		if path != "" && path[0] != '$' {
+			path = "$" + path
+		}
+		if ti.Arg.JSONPath, err = jsonpath.Parse(path); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %s: %w", argParsed.JSONPath.Value, err)
+		}
+		// the template is executed with one object, so the path can't select several
+		if ti.Arg.JSONPath.Query().Singular() == nil {
+			return nil, fmt.Errorf("jsonpath %s of a template must select a single object, ex: .services.web", argParsed.JSONPath.Value)
+		}
+	}
+	if argParsed.Out != nil {
+		ti.Arg.Output = argParsed.Out.Value
+		if ti.Arg.Output != "-" {
+			ti.Arg.Output = loader.NormalizeFilepath(ti.Arg.Output)
+		}
+	}
+	if argParsed.Mode != nil {
+		if ti.Arg.Mode, err = ParseFileMode(argParsed.Mode.Value); err != nil {
+			return nil, err
+		}
+	}
 
 	return ti, nil
 }
+
+// argInput returns the input of the template argument ti is from: the root of its container, or ti itself.
+func (ti *Input) argInput() *Input {
+	if ti.Container.Root != nil {
+		return ti.Container.Root
+	}
+	return ti
+}
+
+// ArgOutput returns the out= of the template argument ti is from, "" if it has none.
+func (ti *Input) ArgOutput() string {
+	return ti.argInput().Arg.Output
+}
+
+// ArgMode returns the mode= of the template argument ti is from, 0 if it has none.
+func (ti *Input) ArgMode() FileMode {
+	return ti.argInput().Arg.Mode
+}
+
+// ScopeData returns the node of data selected by the jsonpath= of the template argument ti is from, which its
+// template is executed with, or data itself if it has none.
+func (ti *Input) ScopeData(data map[string]any) (map[string]any, error) {
+	path := ti.argInput().Arg.JSONPath
+	if path == nil || path.String() == "$" {
+		return data, nil
+	}
+	nodes := path.Select(data)
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("jsonpath %s of template %s selects nothing in the data", path, ti.Name)
+	}
+	scoped, ok := nodes[0].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("jsonpath %s of template %s must select an object, got %T", path, ti.Name, nodes[0])
+	}
+	return scoped, nil
+}
@@ -4,23 +4,40 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/adam-huganir/yutc/pkg/loader"
 	"github.com/rs/zerolog"
+	"github.com/theory/jsonpath"
 )
 
 // Info holds metadata for template file renaming.
 type Info struct {
-	NewName string // For templates, if we are renaming the file, this is the new name
+	NewName     string       // For templates, if we are renaming the file, this is the new name
+	FrontMatter *FrontMatter // The front matter the template file starts with, if any
+	Copy        bool         // The file of a container is copied as is rather than rendered
+	Skip        bool         // A segment of the path of the file renders to an empty string, it is not rendered
 }
 
 // ContainerInfo holds the parent/root/children tree for directory-based template inputs.
 type ContainerInfo struct {
 	Parent   *Input   // Parent of the file if it is a directory or archive
 	Root     *Input   // Root of the file if it is a directory or archive
+	Include  []string // If set, the globs of the only files of the container kept, from include= args
+	Exclude  []string // Globs of the files and directories left out of the container, from exclude= args
 	children []*Input // Children of the file if it is a directory or archive
+	ignore   []ignoreRule
+}
+
+// ArgInfo holds the settings of the template argument of an input, which apply to all the files of a container.
+type ArgInfo struct {
+	JSONPath *jsonpath.Path // If set, the templates are executed with the node of the data it selects, from jsonpath=
+	Output   string         // If set, the output file, or the output directory of a container, from out=, - for stdout
+	Mode     FileMode       // If set, the mode of the output files, from mode=
 }
 
 // Input represents a template or common/shared template file.
@@ -28,6 +45,7 @@ type Input struct {
 	*loader.FileEntry
 	Template  Info
 	Container ContainerInfo
+	Arg       ArgInfo
 	IsCommon  bool // true if this is a common/shared template
 }
 
@@ -40,21 +58,63 @@ func NewInput(name string, isCommon bool, opts ...loader.FileEntryOption) *Input
 	}
 }
 
-// TemplateName resolves the template name by executing the filename as a template with the given data.
+// TemplateName resolves the template name by executing each segment of the path of the file in its container, or
+// of its path if it is not in one, as a template with the given data. A segment rendering to an empty string skips
+// the file, as it does all the files of a directory whose name renders empty.
 func (ti *Input) TemplateName(t *template.Template, data map[string]any) (string, error) {
-	if ti.Template.NewName != "" {
+	if ti.Template.NewName != "" || ti.Template.Skip {
 		return ti.Template.NewName, nil
 	}
-	newName := bytes.NewBufferString("")
-	t, err := t.New(ti.Name).Parse(ti.Name)
+	rel, join := filepath.ToSlash(ti.Name), func(rel string) string { return rel }
+	if root := ti.Container.Root; root != nil && root != ti {
+		rootPath, err := root.IOPath()
+		if err != nil {
+			return "", err
+		}
+		if rel, err = relativeTo(rootPath, ti.Name); err != nil {
+			return "", err
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(ti.Name, rootPath+"#") {
+			join = func(rel string) string { return rootPath + "#" + rel }
+		} else {
+			join = func(rel string) string { return path.Join(filepath.ToSlash(rootPath), rel) }
+		}
+	}
+	segments := strings.Split(rel, "/")
+	for i, segment := range segments {
+		if !strings.Contains(segment, "{{") {
+			continue
+		}
+		rendered, err := renderNameSegment(t, segment, data)
+		if err != nil {
+			return "", err
+		}
+		if rendered == "" {
+			ti.Template.Skip = true
+			return "", nil
+		}
+		segments[i] = rendered
+	}
+	newRel := strings.Join(segments, "/")
+	if ti.Container.Root != nil && ti.Container.Root != ti && !filepath.IsLocal(filepath.FromSlash(newRel)) {
+		return "", fmt.Errorf("the name of %s renders to %s, outside of its container", ti.Name, newRel)
+	}
+	ti.Template.NewName = join(newRel)
+	return ti.Template.NewName, nil
+}
+
+// renderNameSegment executes the path segment as a template with data.
+func renderNameSegment(t *template.Template, segment string, data map[string]any) (string, error) {
+	t, err := t.New(segment).Parse(segment)
 	if err != nil {
 		return "", err
 	}
-	if err := t.ExecuteTemplate(newName, ti.Name, data); err != nil {
+	var rendered bytes.Buffer
+	if err = t.ExecuteTemplate(&rendered, segment, data); err != nil {
 		return "", err
 	}
-	ti.Template.NewName = newName.String()
-	return ti.Template.NewName, nil
+	return rendered.String(), nil
 }
 
 // RelativePath returns the relative path of the file from its root container.
@@ -62,9 +122,7 @@ func (ti *Input) RelativePath() (string, error) {
 	if ti.Container.Root == nil || ti.Container.Root == ti {
 		return filepath.Base(ti.Name), nil
 	}
-	n := filepath.FromSlash(ti.Name)
-	rn := filepath.FromSlash(ti.Container.Root.Name)
-	return filepath.Rel(rn, n)
+	return relativePathIn(ti.Container.Root, ti.Name)
 }
 
 // RelativeNewPath returns the relative path of the file from its root using NewName if available.
@@ -76,9 +134,26 @@ func (ti *Input) RelativeNewPath() (string, error) {
 	if ti.Container.Root == nil || ti.Container.Root == ti {
 		return filepath.Base(name), nil
 	}
-	n := filepath.FromSlash(name)
-	rn := filepath.FromSlash(ti.Container.Root.Name)
-	return filepath.Rel(rn, n)
+	return relativePathIn(ti.Container.Root, name)
+}
+
+// relativePathIn returns the path of name relative to the container root, whose files are named after the path
+// the root is read from: the checkout directory for git sources.
+func relativePathIn(root *Input, name string) (string, error) {
+	rootPath, err := root.IOPath()
+	if err != nil {
+		return "", err
+	}
+	return relativeTo(rootPath, name)
+}
+
+// relativeTo returns the path of name relative to the container root. Files in archives are named
+// <archive>#<path in archive>.
+func relativeTo(root, name string) (string, error) {
+	if inArchive, ok := strings.CutPrefix(name, root+"#"); ok {
+		return filepath.FromSlash(path.Clean(strings.TrimPrefix(inArchive, "/"))), nil
+	}
+	return filepath.Rel(filepath.FromSlash(root), filepath.FromSlash(name))
 }
 
 // AllChildren returns all descendant Input entries (flattened).
@@ -117,7 +192,25 @@ func (ti *Input) CollectContainerChildren() error {
 	if err != nil {
 		return err
 	}
+	root := ti.Container.Root
+	if root == nil {
+		root = ti
+		if err = ti.loadIgnoreFile(entries); err != nil {
+			return err
+		}
+	}
 	for _, entry := range entries {
+		rel, err := relativePathIn(root, entry.Name)
+		if err != nil {
+			return err
+		}
+		isDir, err := entry.IsDir()
+		if err != nil {
+			return err
+		}
+		if root.excludes(filepath.ToSlash(rel), isDir) {
+			continue
+		}
 		child := &Input{
 			FileEntry: entry,
 			IsCommon:  ti.IsCommon,
@@ -133,6 +226,24 @@ func (ti *Input) CollectContainerChildren() error {
 	return nil
 }
 
+// loadIgnoreFile reads the rules of the ignore file among the entries of the root container ti, if any.
+func (ti *Input) loadIgnoreFile(entries []*loader.FileEntry) error {
+	for _, entry := range entries {
+		if rel, err := relativePathIn(ti, entry.Name); err != nil || filepath.ToSlash(rel) != IgnoreFileName {
+			continue
+		}
+		if err := entry.Load(); err != nil {
+			return fmt.Errorf("unable to read %s: %w", entry.Name, err)
+		}
+		rules, err := parseIgnoreFile(string(entry.Content.Data))
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", entry.Name, err)
+		}
+		ti.Container.ignore = rules
+	}
+	return nil
+}
+
 // LoadContainer recursively loads all children of a container Input.
 func (ti *Input) LoadContainer() error {
 	err := ti.CollectContainerChildren()
@@ -141,6 +252,12 @@ func (ti *Input) LoadContainer() error {
 	}
 	if ti.Container.children != nil {
 		for _, child := range ti.Container.children {
+			if isDir, err := child.IsDir(); err != nil {
+				return err
+			} else if isDir {
+				// the files of subdirectories are already listed as children of ti
+				continue
+			}
 			if isContainer, err := child.IsContainer(); err != nil {
 				return err
 			} else if isContainer {
@@ -159,6 +276,44 @@ func (ti *Input) LoadContainer() error {
 	return nil
 }
 
+// MarkCopies marks the files of the containers in inputs, other than partials, that are copied as is rather than
+// rendered: those matching copyGlobs, those not matching templateGlobs if any are given, and otherwise those that are not text.
+// Globs match the path of the file in its container, as in .gitignore.
+func MarkCopies(inputs []*Input, copyGlobs, templateGlobs []string) error {
+	for _, glob := range slices.Concat(copyGlobs, templateGlobs) {
+		if err := loader.ValidateGlob(glob); err != nil {
+			return err
+		}
+	}
+	for _, input := range inputs {
+		for _, child := range input.AllChildren() {
+			if isDir, err := child.IsDir(); err != nil {
+				return err
+			} else if isDir || child.IsPartial() {
+				continue
+			}
+			relativePath, err := child.RelativePath()
+			if err != nil {
+				return err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+			switch {
+			case loader.MatchAnyGlob(copyGlobs, relativePath):
+				child.Template.Copy = true
+			case len(templateGlobs) > 0:
+				child.Template.Copy = !loader.MatchAnyGlob(templateGlobs, relativePath)
+			default:
+				isText, err := child.IsText()
+				if err != nil {
+					return err
+				}
+				child.Template.Copy = !isText
+			}
+		}
+	}
+	return nil
+}
+
 // TemplateFilenames resolves template-based filenames for a list of Input entries.
 func TemplateFilenames(fas []*Input, t *template.Template, data map[string]any) error {
 	for _, fa := range fas {
@@ -172,12 +327,18 @@ func TemplateFilenames(fas []*Input, t *template.Template, data map[string]any)
 
 // ResolveTemplatePaths parses template path strings, loads their content, and expands directories.
 func ResolveTemplatePaths(paths []string, isCommon bool, tempDir string, logger *zerolog.Logger) ([]*Input, error) {
+	return ResolveTemplatePathsRelativeTo(paths, isCommon, "", tempDir, logger)
+}
+
+// ResolveTemplatePathsRelativeTo is ResolveTemplatePaths with relative file paths resolved against dir.
+func ResolveTemplatePathsRelativeTo(paths []string, isCommon bool, dir, tempDir string, logger *zerolog.Logger) ([]*Input, error) {
 	var outFiles []*Input
 	for _, p := range paths {
 		ti, err := ParseTemplateArgWithTempDir(p, isCommon, tempDir)
 		if err != nil {
 			return nil, err
 		}
+		ti.ResolveRelativeTo(dir)
 		ti.SetLogger(logger)
 		err = ti.Load()
 		if err != nil && !errors.Is(err, loader.ErrIsContainer) {
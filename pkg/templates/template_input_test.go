@@ -9,6 +9,7 @@ import (
 
 	"github.com/adam-huganir/yutc/pkg/loader"
 	"github.com/stretchr/testify/assert"
+	"github.com/theory/jsonpath"
 )
 
 func TestInput_ListContainerFiles(t *testing.T) {
@@ -58,6 +59,40 @@ func TestTemplateFilenames(t *testing.T) {
 	assert.Equal(t, "my-project/init.py", ti.Template.NewName)
 }
 
+func TestTemplateName_Segments(t *testing.T) {
+	tests := []struct {
+		name         string
+		root         string
+		path         string
+		expected     string
+		expectedSkip bool
+		expectedErr  string
+	}{
+		{name: "directory", root: "project", path: "project/{{ .name }}/main.go", expected: "project/app/main.go"},
+		{name: "root not rendered", root: "{{ .name }}", path: "{{ .name }}/{{ .name }}.go", expected: "{{ .name }}/app.go"},
+		{name: "archive", root: "project.tgz", path: "project.tgz#{{ .name }}/main.go", expected: "project.tgz#app/main.go"},
+		{name: "empty directory segment", root: "project", path: "project/{{ if .docs }}docs{{ end }}/index.md", expectedSkip: true},
+		{name: "empty file segment", root: "project.zip", path: "project.zip#src/{{ if .docs }}doc.go{{ end }}", expectedSkip: true},
+		{name: "outside the container", root: "project", path: "project/{{ .up }}/main.go", expectedErr: "outside of its container"},
+	}
+	data := map[string]any{"name": "app", "docs": false, "up": ".."}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewInput(tt.root, false, loader.WithSource(loader.SourceKindFile))
+			ti := NewInput(tt.path, false, loader.WithSource(loader.SourceKindFile))
+			ti.Container.Root = root
+			newName, err := ti.TemplateName(template.New("test"), data)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, newName)
+			assert.Equal(t, tt.expectedSkip, ti.Template.Skip)
+		})
+	}
+}
+
 func TestTemplateFilenames_Error(t *testing.T) {
 	tmpl := template.Must(template.New("test").Parse("{{ .project_name }}"))
 	tiInvalid := NewInput("{{ .Unclosed", false, loader.WithSource(loader.SourceKindFile), loader.WithContentBytes([]byte("content")))
@@ -74,6 +109,9 @@ func TestParseTemplateArg(t *testing.T) {
 		isCommon            bool
 		expectedBearerToken string
 		expectedBasicAuth   string
+		expectedJSONPath    string
+		expectedOutput      string
+		expectedMode        FileMode
 	}{
 		{
 			name:         "simple template path",
@@ -81,9 +119,44 @@ func TestParseTemplateArg(t *testing.T) {
 			expectedPath: "my_template.tmpl",
 		},
 		{
-			name:        "template and a jsonpath (error)",
+			name:             "template with a jsonpath and out",
+			input:            "jsonpath=.services.web,src=./svc.tmpl,out=./out/web.yaml",
+			expectedPath:     "svc.tmpl",
+			expectedJSONPath: `$["services"]["web"]`,
+			expectedOutput:   "out/web.yaml",
+		},
+		{
+			name:        "template with a jsonpath selecting several nodes (error)",
+			input:       "jsonpath=.services[*],src=./svc.tmpl",
+			expectError: "jsonpath .services[*] of a template must select a single object",
+		},
+		{
+			name:           "template from stdin to stdout with a mode",
+			input:          "src=-,out=-,mode=755",
+			expectedPath:   "-",
+			expectedOutput: "-",
+			expectedMode:   0o755,
+		},
+		{
+			name:        "template with an invalid mode (error)",
+			input:       "src=./run.sh.tmpl,mode=rwx",
+			expectError: "invalid mode rwx",
+		},
+		{
+			name:        "common template and a jsonpath (error)",
 			input:       "jsonpath=.test,src=something.tmpl",
-			expectError: "key parameter is not supported for template arguments",
+			isCommon:    true,
+			expectError: "jsonpath, out and mode parameters are not supported for common template arguments",
+		},
+		{
+			name:        "template and a format (error)",
+			input:       "format=json,src=something.tmpl",
+			expectError: "format parameter is not supported for template arguments",
+		},
+		{
+			name:        "template from env (error)",
+			input:       "src=env,type=env",
+			expectError: "env source is not supported for template arguments",
 		},
 		{
 			name:         "common template",
@@ -144,6 +217,11 @@ func TestParseTemplateArg(t *testing.T) {
 			assert.Equal(t, tt.isCommon, result.IsCommon)
 			assert.Equal(t, tt.expectedBearerToken, result.Auth.BearerToken)
 			assert.Equal(t, tt.expectedBasicAuth, result.Auth.BasicAuth)
+			if tt.expectedJSONPath != "" {
+				assert.Equal(t, tt.expectedJSONPath, result.Arg.JSONPath.String())
+			}
+			assert.Equal(t, tt.expectedOutput, result.Arg.Output)
+			assert.Equal(t, tt.expectedMode, result.Arg.Mode)
 			if tt.name == "git known host template source" {
 				assert.Equal(t, loader.SourceKindGit, result.Source)
 				assert.NotNil(t, result.Git)
@@ -158,3 +236,55 @@ func TestParseTemplateArg(t *testing.T) {
 		})
 	}
 }
+
+func TestInput_ScopeData(t *testing.T) {
+	data := map[string]any{"services": map[string]any{"api": map[string]any{"port": 80}, "names": []any{"api"}}}
+	tests := []struct {
+		name        string
+		jsonpath    string
+		expected    map[string]any
+		expectedErr string
+	}{
+		{name: "no jsonpath", expected: data},
+		{name: "object", jsonpath: "$.services.api", expected: map[string]any{"port": 80}},
+		{name: "nothing selected", jsonpath: "$.services.web", expectedErr: "selects nothing in the data"},
+		{name: "not an object", jsonpath: "$.services.names", expectedErr: "must select an object, got []interface {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := NewInput("templates", false)
+			if tt.jsonpath != "" {
+				root.Arg.JSONPath = jsonpath.MustParse(tt.jsonpath)
+			}
+			child := NewInput("templates/svc.tmpl", false)
+			child.Container.Root = root
+			scoped, err := child.ScopeData(data)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, scoped)
+		})
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		path     string
+		expected string
+	}{
+		{name: "file in directory", root: "project", path: "project/src/main.go", expected: filepath.Join("src", "main.go")},
+		{name: "file in archive", root: "project.tgz", path: "project.tgz#./src/main.go", expected: filepath.Join("src", "main.go")},
+		{name: "file at archive root", root: "project.zip", path: "project.zip#main.go", expected: "main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, err := relativeTo(tt.root, tt.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rel)
+		})
+	}
+}
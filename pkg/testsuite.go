@@ -0,0 +1,173 @@
+package yutc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/adam-huganir/yutc/pkg/coverage"
+	"github.com/adam-huganir/yutc/pkg/loader"
+	yutcTemplate "github.com/adam-huganir/yutc/pkg/templates"
+	"github.com/adam-huganir/yutc/pkg/types"
+	"github.com/adam-huganir/yutc/pkg/unittest"
+	"github.com/rs/zerolog"
+)
+
+// defaultTestDir is where yutc test looks for suites when none are given.
+const defaultTestDir = "tests"
+
+// Test runs the test suites of args, suite files or directories holding them, or those in ./tests if none are
+// given, and writes the results to the configured output as text or JUnit XML. It returns a *types.ExitError
+// if any test fails.
+func (app *App) Test(_ context.Context, args []string) (err error) {
+	if app.Logger.GetLevel() < zerolog.DebugLevel {
+		app.LogSettings()
+	}
+
+	switch app.Settings.TestFormat {
+	case "", "text", "junit":
+	default:
+		return fmt.Errorf("invalid test format %q: must be one of: text, junit", app.Settings.TestFormat)
+	}
+
+	if len(args) == 0 {
+		args = []string{defaultTestDir}
+	}
+	suitePaths, err := findSuites(args)
+	if err != nil {
+		return err
+	}
+
+	if app.Settings.Coverage != "" {
+		app.coverage = coverage.NewProfile()
+	}
+	runner := &unittest.Runner{Render: app.renderTest, UpdateSnapshots: app.Settings.UpdateSnapshots}
+	var results []*unittest.SuiteResult
+	for _, suitePath := range suitePaths {
+		content, err := os.ReadFile(suitePath)
+		if err != nil {
+			return err
+		}
+		suite, err := unittest.ParseSuite(suitePath, content)
+		if err != nil {
+			return err
+		}
+		result, err := runner.Run(suite)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+
+	var out bytes.Buffer
+	if app.Settings.TestFormat == "junit" {
+		err = unittest.WriteJUnit(&out, results)
+	} else {
+		err = unittest.WriteText(&out, results)
+	}
+	if err != nil {
+		return err
+	}
+	if err = app.writeOutput(out.Bytes()); err != nil {
+		return err
+	}
+	if err = app.writeCoverage(); err != nil {
+		return err
+	}
+	if tests, failed, errored := unittest.Totals(results); failed+errored > 0 {
+		return &types.ExitError{Code: 1, Err: fmt.Errorf("%d of %d test(s) failed", failed+errored, tests)}
+	}
+	return nil
+}
+
+// findSuites returns the suite files of paths, in order: files as is and the .yaml and .yml files directly in
+// directories, sorted.
+func findSuites(paths []string) ([]string, error) {
+	var suites []string
+	for _, path := range paths {
+		isDir, err := loader.IsDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find test suites in %s: %w", path, err)
+		}
+		if !isDir {
+			suites = append(suites, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, entry := range entries {
+			if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				suites = append(suites, filepath.Join(path, entry.Name()))
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no test suites (*.yaml, *.yml) in %s", path)
+		}
+	}
+	return suites, nil
+}
+
+// renderTest renders the templates of a test as yutc would with the suite's and test's arguments, with paths
+// relative to the suite file. --allow-shell and --auth apply to every test, and --coverage collects the coverage
+// of all of them.
+func (app *App) renderTest(suite *unittest.Suite, test *unittest.Test) (rendered []unittest.Rendered, err error) {
+	dropExtension := "tmpl"
+	if suite.DropExtension != nil {
+		dropExtension = *suite.DropExtension
+	}
+	testApp := NewApp(&types.Arguments{
+		DataFiles:           slices.Concat(suite.Data, test.Data),
+		SetData:             slices.Concat(suite.Set, test.Set),
+		SetString:           slices.Concat(suite.SetString, test.SetString),
+		SetJSON:             slices.Concat(suite.SetJSON, test.SetJSON),
+		CommonTemplateFiles: suite.CommonTemplates,
+		TemplatePaths:       suite.TemplatesOf(test),
+		Helm:                suite.Helm,
+		Strict:              suite.Strict,
+		AllowShell:          app.Settings.AllowShell,
+		Auth:                app.Settings.Auth,
+		DropExtension:       dropExtension,
+	}, &RunData{}, app.Logger)
+	testApp.baseDir = suite.Dir()
+	testApp.coverage = app.coverage
+	defer testApp.cleanupTempDir()
+
+	globalAuth := testApp.globalAuth()
+	if err = testApp.resolveTemplateFiles(globalAuth); err != nil {
+		return nil, err
+	}
+	if err = testApp.resolveDataFiles(globalAuth); err != nil {
+		return nil, err
+	}
+	if err = testApp.mergeData(); err != nil {
+		return nil, err
+	}
+	templateSet, err := testApp.loadTemplateSet()
+	if err != nil {
+		return nil, err
+	}
+	err = testApp.renderEach(templateSet, func(templateFile *yutcTemplate.Input, r *renderedTemplate) error {
+		outputPath := r.relativePath
+		if output := templateFile.ArgOutput(); output != "" && output != "-" {
+			// out= is relative to the output directory, and the output directory of the files of a container
+			if templateFile.Container.Root != nil {
+				outputPath = filepath.Join(output, r.relativePath)
+			} else {
+				outputPath = output
+			}
+		}
+		rendered = append(rendered, unittest.Rendered{Template: filepath.ToSlash(filepath.Clean(outputPath)), Output: r.output})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rendered, nil
+}
@@ -1,5 +1,11 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // ExitError represents an error with an associated exit code for CLI commands.
 type ExitError struct {
 	Code int
@@ -42,10 +48,33 @@ func (e *RuntimeError) Unwrap() error {
 	return e.Err
 }
 
-// TemplateError represents an error that occurred during template execution.
+// TemplateError represents an error that occurred while parsing or executing a template. The location and
+// context fields are set when they can be recovered from the error.
 type TemplateError struct {
-	TemplatePath string
-	Err          error
+	TemplatePath string          `json:"template"`
+	Line         int             `json:"line,omitempty"`       // line of the error in the template, from 1
+	Column       int             `json:"column,omitempty"`     // column of the error in the line, from 1
+	Expression   string          `json:"expression,omitempty"` // the action being evaluated
+	DataPath     string          `json:"dataPath,omitempty"`   // the data being evaluated, from the root of the data
+	Hint         string          `json:"hint,omitempty"`       // what was wrong with the data, ex: ".Values.image is nil"
+	Message      string          `json:"message"`              // the error, without the template locations
+	Snippet      []SourceLine    `json:"snippet,omitempty"`    // the lines of the template around the error
+	Stack        []TemplateFrame `json:"stack,omitempty"`      // the include and tpl calls leading to the error, outermost first
+	Err          error           `json:"-"`
+}
+
+// SourceLine is a numbered line of a template.
+type SourceLine struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+// TemplateFrame is an include or tpl call leading to a template error.
+type TemplateFrame struct {
+	TemplatePath string `json:"template"`
+	Line         int    `json:"line"`
+	Column       int    `json:"column,omitempty"`
+	Expression   string `json:"expression,omitempty"`
 }
 
 func (e *TemplateError) Error() string {
@@ -59,6 +88,72 @@ func (e *TemplateError) Unwrap() error {
 	return e.Err
 }
 
+// Pretty renders the error over several lines: its location and message, the template source with a caret
+// under the error, the data being evaluated and the calls leading to it. With color, ANSI escape codes
+// highlight the parts.
+func (e *TemplateError) Pretty(color bool) string {
+	style := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return "\x1b[" + code + "m" + s + "\x1b[0m"
+	}
+	location := func(path string, line, column int) string {
+		if line > 0 {
+			path += ":" + strconv.Itoa(line)
+			if column > 0 {
+				path += ":" + strconv.Itoa(column)
+			}
+		}
+		return path
+	}
+
+	var b strings.Builder
+	b.WriteString(style("1", location(e.TemplatePath, e.Line, e.Column)+": ") + style("31", e.Message) + "\n")
+	width := 0
+	for _, line := range e.Snippet {
+		width = max(width, len(strconv.Itoa(line.Number)))
+	}
+	for _, line := range e.Snippet {
+		marker := "  "
+		if line.Number == e.Line {
+			marker = style("31", "> ")
+		}
+		fmt.Fprintf(&b, "  %s%*d | %s\n", marker, width, line.Number, line.Text)
+		if line.Number == e.Line && e.Column > 0 {
+			// the column counts bytes, keep tabs so the caret lines up with the source
+			indent := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, line.Text[:min(e.Column-1, len(line.Text))])
+			fmt.Fprintf(&b, "    %*s | %s%s\n", width, "", indent, style("31", "^"))
+		}
+	}
+	if e.DataPath != "" {
+		b.WriteString("  evaluating " + style("36", e.DataPath))
+		if e.Hint != "" {
+			b.WriteString(": " + e.Hint)
+		}
+		b.WriteString("\n")
+	} else if e.Expression != "" {
+		b.WriteString("  evaluating " + style("36", e.Expression) + "\n")
+	}
+	if len(e.Stack) > 0 {
+		b.WriteString("  called from:\n")
+		for i := len(e.Stack) - 1; i >= 0; i-- {
+			frame := e.Stack[i]
+			b.WriteString("    " + location(frame.TemplatePath, frame.Line, frame.Column))
+			if frame.Expression != "" {
+				b.WriteString(" " + style("36", frame.Expression))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
 type DedentError struct {
 	Line   string
 	Prefix string
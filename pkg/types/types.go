@@ -5,19 +5,27 @@ package types // nolint:revive // make this a better name at some point?
 type Arguments struct {
 	DataFiles []string `json:"data-files"`
 	SetData   []string `json:"set-data"`
+	SetString []string `json:"set-string"`
+	SetFile   []string `json:"set-file"`
+	SetJSON   []string `json:"set-json"`
+	Unset     []string `json:"unset"`
 	// DataMatch []string `json:"data-match"`
 
 	CommonTemplateFiles []string `json:"common-templates"`
 	// CommonTemplateMatch []string `json:"common-templates-match"`
 
-	TemplatePaths []string `json:"template-files"`
+	TemplatePaths []string `json:"template-files"` // template args, whose out= and mode= keys take precedence over Output
 	// TemplateMatch []string `json:"template-match"`
 
-	Output           string `json:"output"`
-	IgnoreEmpty      bool   `json:"ignore-empty"`
-	IncludeFilenames bool   `json:"include-filenames"`
-	Overwrite        bool   `json:"overwrite"`
-	Helm             bool   `json:"helm"`
+	Output           string   `json:"output"`
+	IgnoreEmpty      bool     `json:"ignore-empty"`
+	IncludeFilenames bool     `json:"include-filenames"`
+	CopyGlobs        []string `json:"copy-glob"`     // files of template directories copied as is
+	TemplateGlobs    []string `json:"template-glob"` // if set, the only files of template directories rendered
+	Overwrite        bool     `json:"overwrite"`
+	Helm             bool     `json:"helm"`
+	ResolveRefs      bool     `json:"resolve-refs"`
+	Prompt           bool     `json:"prompt"`
 
 	Strict     bool
 	AllowShell bool `json:"allow-shell"`
@@ -25,8 +33,25 @@ type Arguments struct {
 	Version bool `json:"version"`
 	Verbose bool `json:"verbose"`
 
+	ErrorFormat string `json:"error-format"` // how errors are printed to stderr, text or json
+	Coverage    string `json:"coverage"`     // file to write the template coverage report to, as HTML if it ends in .html
+
 	Auth          string `json:"auth"`
 	DropExtension string `json:"drop-extension"`
+
+	// settings for the `data` subcommand
+	DataFormat   string `json:"data-format"`
+	DataJSONPath string `json:"data-jsonpath"`
+
+	// settings for the `schema infer` subcommand
+	SchemaFormat string `json:"schema-format"`
+
+	// settings for the `lint` subcommand
+	LintFormat string `json:"lint-format"`
+
+	// settings for the `test` subcommand
+	TestFormat      string `json:"test-format"`
+	UpdateSnapshots bool   `json:"update-snapshots"`
 }
 
 // NewCLISettings creates and returns a new Arguments struct with default values.
@@ -0,0 +1,117 @@
+package unittest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Totals returns the number of tests, failed tests and tests in error of the results.
+func Totals(results []*SuiteResult) (tests, failed, errored int) {
+	for _, r := range results {
+		tests += len(r.Tests)
+		failed += r.Count(StatusFailed)
+		errored += r.Count(StatusError)
+	}
+	return tests, failed, errored
+}
+
+// WriteText writes a line per test, the messages of those that didn't pass and a summary.
+func WriteText(w io.Writer, results []*SuiteResult) error {
+	var b strings.Builder
+	for _, r := range results {
+		for _, test := range r.Tests {
+			label := map[Status]string{StatusPassed: "PASS", StatusFailed: "FAIL", StatusError: "ERROR"}[test.Status]
+			fmt.Fprintf(&b, "%-5s %s/%s (%s)\n", label, r.Name, test.Name, test.Duration.Round(time.Millisecond))
+			for _, message := range test.Messages {
+				fmt.Fprintf(&b, "      %s\n", strings.ReplaceAll(message, "\n", "\n      "))
+			}
+		}
+	}
+	tests, failed, errored := Totals(results)
+	fmt.Fprintf(&b, "%d tests, %d passed, %d failed, %d errors\n", tests, tests-failed-errored, failed, errored)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// The subset of the JUnit XML format written by WriteJUnit.
+type (
+	junitSuites struct {
+		XMLName  xml.Name     `xml:"testsuites"`
+		Name     string       `xml:"name,attr"`
+		Tests    int          `xml:"tests,attr"`
+		Failures int          `xml:"failures,attr"`
+		Errors   int          `xml:"errors,attr"`
+		Time     string       `xml:"time,attr"`
+		Suites   []junitSuite `xml:"testsuite"`
+	}
+	junitSuite struct {
+		Name     string      `xml:"name,attr"`
+		File     string      `xml:"file,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Errors   int         `xml:"errors,attr"`
+		Time     string      `xml:"time,attr"`
+		Cases    []junitCase `xml:"testcase"`
+	}
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitProblem `xml:"failure,omitempty"`
+		Error     *junitProblem `xml:"error,omitempty"`
+	}
+	junitProblem struct {
+		Message string `xml:"message,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// WriteJUnit writes the results as a JUnit XML report, a testsuite per suite, for CI systems.
+func WriteJUnit(w io.Writer, results []*SuiteResult) error {
+	report := junitSuites{Name: "yutc"}
+	var total time.Duration
+	for _, r := range results {
+		suite := junitSuite{
+			Name:     r.Name,
+			File:     r.Path,
+			Tests:    len(r.Tests),
+			Failures: r.Count(StatusFailed),
+			Errors:   r.Count(StatusError),
+			Time:     seconds(r.Duration),
+		}
+		for _, test := range r.Tests {
+			testCase := junitCase{Name: test.Name, ClassName: r.Name, Time: seconds(test.Duration)}
+			if test.Status != StatusPassed {
+				problem := &junitProblem{Message: test.Messages[0], Text: strings.Join(test.Messages, "\n")}
+				if test.Status == StatusError {
+					testCase.Error = problem
+				} else {
+					testCase.Failure = problem
+				}
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		report.Suites = append(report.Suites, suite)
+		total += r.Duration
+	}
+	report.Tests, report.Failures, report.Errors = Totals(results)
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
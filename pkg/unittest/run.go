@@ -0,0 +1,294 @@
+package unittest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Rendered is the output of a template rendered by a test.
+type Rendered struct {
+	Template string // path of the output relative to the output directory, with forward slashes
+	Output   []byte
+}
+
+// RenderFunc renders the templates of a test of suite with its data.
+type RenderFunc func(suite *Suite, test *Test) ([]Rendered, error)
+
+// Runner runs test suites.
+type Runner struct {
+	Render RenderFunc
+	// UpdateSnapshots writes the rendered output to missing or differing snapshots rather than failing.
+	UpdateSnapshots bool
+}
+
+// Status is the outcome of a test.
+type Status string
+
+const (
+	StatusPassed Status = "passed"
+	StatusFailed Status = "failed" // an assertion failed
+	StatusError  Status = "error"  // the templates failed to render and no assertion expected it
+)
+
+// TestResult is the outcome of a test.
+type TestResult struct {
+	Name     string
+	Status   Status
+	Messages []string // the failed assertions, or the error
+	Duration time.Duration
+}
+
+// SuiteResult is the outcome of the tests of a suite.
+type SuiteResult struct {
+	Name     string
+	Path     string
+	Tests    []TestResult
+	Duration time.Duration
+}
+
+// Count returns the number of tests of the suite with the status.
+func (r *SuiteResult) Count(status Status) int {
+	n := 0
+	for _, test := range r.Tests {
+		if test.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Run runs the tests of the suite. It returns an error only if the snapshots can't be read or written.
+func (r *Runner) Run(suite *Suite) (*SuiteResult, error) {
+	start := time.Now()
+	snapshots, err := loadSnapshots(suite, r.UpdateSnapshots)
+	if err != nil {
+		return nil, err
+	}
+	result := &SuiteResult{Name: suite.Name, Path: suite.Path}
+	for _, test := range suite.Tests {
+		testStart := time.Now()
+		testResult := r.runTest(suite, test, snapshots)
+		testResult.Duration = time.Since(testStart)
+		result.Tests = append(result.Tests, testResult)
+	}
+	if err = snapshots.save(); err != nil {
+		return nil, err
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+func (r *Runner) runTest(suite *Suite, test *Test, snapshots *snapshotStore) TestResult {
+	result := TestResult{Name: test.Name, Status: StatusPassed}
+	rendered, renderErr := r.Render(suite, test)
+	if renderErr != nil && !expectsError(test) {
+		result.Status = StatusError
+		result.Messages = []string{renderErr.Error()}
+		return result
+	}
+	for i, assert := range test.Asserts {
+		var err error
+		if assert.ErrorContains != nil {
+			err = checkError(renderErr, *assert.ErrorContains)
+		} else if renderErr != nil {
+			err = fmt.Errorf("templates failed to render: %w", renderErr)
+		} else {
+			err = r.check(&assert, test, rendered, snapshots)
+		}
+		if err != nil {
+			result.Status = StatusFailed
+			result.Messages = append(result.Messages, fmt.Sprintf("assert %d: %s", i+1, err))
+		}
+	}
+	return result
+}
+
+func expectsError(test *Test) bool {
+	for _, assert := range test.Asserts {
+		if assert.ErrorContains != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func checkError(err error, contains string) error {
+	if err == nil {
+		return fmt.Errorf("templates rendered, expected an error containing %q", contains)
+	}
+	if !strings.Contains(err.Error(), contains) {
+		return fmt.Errorf("error %q does not contain %q", err.Error(), contains)
+	}
+	return nil
+}
+
+func (r *Runner) check(assert *Assertion, test *Test, rendered []Rendered, snapshots *snapshotStore) error {
+	switch {
+	case assert.Snapshot != nil:
+		selected, err := selectTemplates(rendered, assert.Snapshot.Template)
+		if err != nil {
+			return err
+		}
+		if assert.Snapshot.File != "" {
+			if len(selected) != 1 {
+				return fmt.Errorf("snapshot file %s needs exactly one template, %d were rendered", assert.Snapshot.File, len(selected))
+			}
+			return snapshots.checkFile(assert.Snapshot.File, selected[0])
+		}
+		var errs []error
+		for _, out := range selected {
+			errs = append(errs, snapshots.check(test.Name, out))
+		}
+		return errors.Join(errs...)
+	case assert.Equal != nil:
+		want, err := normalize(assert.Equal.Value)
+		if err != nil {
+			return err
+		}
+		return checkPath(rendered, &assert.Equal.PathAssertion, func(values []any) error {
+			if len(values) == 0 {
+				return errors.New("matches nothing")
+			}
+			for _, value := range values {
+				if !reflect.DeepEqual(value, want) {
+					return fmt.Errorf("is %s, expected %s", formatValue(value), formatValue(want))
+				}
+			}
+			return nil
+		})
+	case assert.MatchRegex != nil:
+		return checkPath(rendered, &assert.MatchRegex.PathAssertion, func(values []any) error {
+			if len(values) == 0 {
+				return errors.New("matches nothing")
+			}
+			for _, value := range values {
+				s, ok := value.(string)
+				if !ok {
+					s = formatValue(value)
+				}
+				if !assert.MatchRegex.regex.MatchString(s) {
+					return fmt.Errorf("is %s, which does not match %q", formatValue(value), assert.MatchRegex.Pattern)
+				}
+			}
+			return nil
+		})
+	case assert.Exists != nil:
+		return checkPath(rendered, assert.Exists, func(values []any) error {
+			if len(values) == 0 {
+				return errors.New("matches nothing")
+			}
+			return nil
+		})
+	case assert.NotExists != nil:
+		return checkPath(rendered, assert.NotExists, func(values []any) error {
+			if len(values) > 0 {
+				return fmt.Errorf("matches %s", formatValue(values))
+			}
+			return nil
+		})
+	}
+	return nil
+}
+
+// selectTemplates returns the rendered templates whose path is template or ends with /template, or all of them
+// if template is empty.
+func selectTemplates(rendered []Rendered, template string) ([]Rendered, error) {
+	if template == "" {
+		return rendered, nil
+	}
+	template = path.Clean(strings.TrimPrefix(template, "./"))
+	var selected []Rendered
+	for _, out := range rendered {
+		if out.Template == template || strings.HasSuffix(out.Template, "/"+template) {
+			selected = append(selected, out)
+		}
+	}
+	if len(selected) == 0 {
+		names := make([]string, len(rendered))
+		for i, out := range rendered {
+			names[i] = out.Template
+		}
+		return nil, fmt.Errorf("no rendered template matches %s, rendered: %s", template, strings.Join(names, ", "))
+	}
+	return selected, nil
+}
+
+// checkPath calls check with the values selected by the query of p in the document of each template it selects.
+func checkPath(rendered []Rendered, p *PathAssertion, check func(values []any) error) error {
+	selected, err := selectTemplates(rendered, p.Template)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, out := range selected {
+		documents, err := parseDocuments(out.Output)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", out.Template, err))
+			continue
+		}
+		if p.Document >= len(documents) {
+			// a missing document has no values, which only not-exists expects
+			if check(nil) != nil {
+				errs = append(errs, fmt.Errorf("%s: has %d document(s), no document %d", out.Template, len(documents), p.Document))
+			}
+			continue
+		}
+		if err = check(p.query.Select(documents[p.Document])); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s %w", out.Template, p.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseDocuments parses output as a stream of YAML documents, skipping empty ones, with values normalized as
+// JSON values.
+func parseDocuments(output []byte) ([]any, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(output))
+	var documents []any
+	for {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("output is not YAML: %w", err)
+		}
+		if document == nil {
+			continue
+		}
+		if document, err = normalize(document); err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// normalize converts v to the values decoded from JSON, so numbers of any type compare equal.
+func normalize(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(b, &out)
+	return out, err
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
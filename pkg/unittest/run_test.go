@@ -0,0 +1,185 @@
+package unittest
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renderer returns a RenderFunc rendering the outputs of each test by name, failing the test named "fails".
+func renderer(outputs map[string][]Rendered) RenderFunc {
+	return func(_ *Suite, test *Test) ([]Rendered, error) {
+		if test.Name == "fails" {
+			return nil, errors.New(`template: app.tmpl:1:3: executing "app.tmpl" at <fail "boom">: boom`)
+		}
+		return outputs[test.Name], nil
+	}
+}
+
+func writeSuite(t *testing.T, dir, content string) *Suite {
+	t.Helper()
+	path := filepath.Join(dir, "suite.yaml")
+	suite, err := ParseSuite(path, []byte(content))
+	require.NoError(t, err)
+	return suite
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	suite := writeSuite(t, dir, `
+templates: [src]
+tests:
+  - name: values
+    asserts:
+      - equal: {template: app.yaml, path: .replicas, value: 2}
+      - equal: {template: app.yaml, document: 1, path: .kind, value: Service}
+      - match-regex: {template: app.yaml, path: .image, pattern: "^nginx:"}
+      - exists: {template: app.yaml, path: .labels.app}
+      - not-exists: {template: app.yaml, path: .labels.tier}
+  - name: wrong values
+    asserts:
+      - equal: {path: .replicas, value: 3}
+      - exists: {path: .missing}
+      - match-regex: {path: .image, pattern: "^redis"}
+      - equal: {template: nope.yaml, path: .replicas, value: 2}
+  - name: fails
+    asserts:
+      - error-contains: boom
+      - error-contains: other
+  - name: renders without error
+    asserts:
+      - error-contains: boom
+`)
+	app := []Rendered{{Template: "sub/app.yaml", Output: []byte("---\nreplicas: 2\nimage: nginx:1.27\nlabels:\n  app: demo\n---\nkind: Service\n")}}
+	runner := &Runner{Render: renderer(map[string][]Rendered{
+		"values":                app,
+		"wrong values":          app,
+		"renders without error": app,
+	})}
+
+	result, err := runner.Run(suite)
+	require.NoError(t, err)
+	require.Len(t, result.Tests, 4)
+
+	assert.Equal(t, StatusPassed, result.Tests[0].Status, result.Tests[0].Messages)
+
+	assert.Equal(t, StatusFailed, result.Tests[1].Status)
+	assert.Equal(t, []string{
+		"assert 1: sub/app.yaml: .replicas is 2, expected 3",
+		"assert 2: sub/app.yaml: .missing matches nothing",
+		`assert 3: sub/app.yaml: .image is "nginx:1.27", which does not match "^redis"`,
+		"assert 4: no rendered template matches nope.yaml, rendered: sub/app.yaml",
+	}, result.Tests[1].Messages)
+
+	assert.Equal(t, StatusFailed, result.Tests[2].Status)
+	require.Len(t, result.Tests[2].Messages, 1)
+	assert.True(t, strings.HasPrefix(result.Tests[2].Messages[0], "assert 2: error "), result.Tests[2].Messages[0])
+
+	assert.Equal(t, StatusFailed, result.Tests[3].Status)
+	assert.Equal(t, []string{`assert 1: templates rendered, expected an error containing "boom"`}, result.Tests[3].Messages)
+
+	assert.Equal(t, 1, result.Count(StatusPassed))
+	assert.Equal(t, 3, result.Count(StatusFailed))
+}
+
+func TestRunner_RenderError(t *testing.T) {
+	suite := writeSuite(t, t.TempDir(), "templates: [src]\ntests:\n  - name: fails\n    asserts:\n      - exists: {path: .a}\n")
+	result, err := (&Runner{Render: renderer(nil)}).Run(suite)
+	require.NoError(t, err)
+	assert.Equal(t, StatusError, result.Tests[0].Status)
+	assert.Contains(t, result.Tests[0].Messages[0], "boom")
+}
+
+func TestRunner_Snapshots(t *testing.T) {
+	dir := t.TempDir()
+	suite := writeSuite(t, dir, `
+templates: [src]
+tests:
+  - name: stored
+    asserts:
+      - snapshot: {}
+  - name: file
+    asserts:
+      - snapshot: {template: b.txt, file: expected/b.txt}
+`)
+	outputs := map[string][]Rendered{
+		"stored": {{Template: "a.yaml", Output: []byte("a: 1\nb: |\n  text\n")}, {Template: "b.txt", Output: []byte("b\n")}},
+		"file":   {{Template: "a.yaml", Output: []byte("a: 1\n")}, {Template: "b.txt", Output: []byte("b\n")}},
+	}
+	runner := &Runner{Render: renderer(outputs)}
+
+	result, err := runner.Run(suite)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, result.Tests[0].Status)
+	assert.Contains(t, result.Tests[0].Messages[0], "no snapshot of a.yaml")
+	assert.Contains(t, result.Tests[1].Messages[0], "snapshot file expected/b.txt does not exist")
+
+	runner.UpdateSnapshots = true
+	result, err = runner.Run(suite)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Count(StatusPassed))
+	content, err := os.ReadFile(filepath.Join(dir, "expected", "b.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(content))
+	assert.FileExists(t, filepath.Join(dir, "__snapshot__", "suite.snap"))
+
+	runner.UpdateSnapshots = false
+	result, err = runner.Run(suite)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Count(StatusPassed), result.Tests)
+
+	outputs["stored"][0].Output = []byte("a: 2\nb: |\n  text\n")
+	outputs["file"][1].Output = []byte("b\nc\n")
+	result, err = runner.Run(suite)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`assert 1: a.yaml differs from its snapshot: line 1 is "a: 2", expected "a: 1"`}, result.Tests[0].Messages)
+	assert.Equal(t, []string{`assert 1: b.txt differs from expected/b.txt: line 2 is "c", expected ""`}, result.Tests[1].Messages)
+}
+
+func TestWriteReports(t *testing.T) {
+	results := []*SuiteResult{{
+		Name:     "app",
+		Path:     "tests/app.yaml",
+		Duration: 1500 * time.Millisecond,
+		Tests: []TestResult{
+			{Name: "passes", Status: StatusPassed, Duration: time.Millisecond},
+			{Name: "fails", Status: StatusFailed, Messages: []string{"assert 1: a", "assert 2: b\nc"}},
+			{Name: "errors", Status: StatusError, Messages: []string{"boom & <bang>"}},
+		},
+	}}
+
+	var text bytes.Buffer
+	require.NoError(t, WriteText(&text, results))
+	assert.Equal(t, `PASS  app/passes (1ms)
+FAIL  app/fails (0s)
+      assert 1: a
+      assert 2: b
+      c
+ERROR app/errors (0s)
+      boom & <bang>
+3 tests, 1 passed, 1 failed, 1 errors
+`, text.String())
+
+	var junit bytes.Buffer
+	require.NoError(t, WriteJUnit(&junit, results))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="yutc" tests="3" failures="1" errors="1" time="1.500">
+  <testsuite name="app" file="tests/app.yaml" tests="3" failures="1" errors="1" time="1.500">
+    <testcase name="passes" classname="app" time="0.001"></testcase>
+    <testcase name="fails" classname="app" time="0.000">
+      <failure message="assert 1: a">assert 1: a&#xA;assert 2: b&#xA;c</failure>
+    </testcase>
+    <testcase name="errors" classname="app" time="0.000">
+      <error message="boom &amp; &lt;bang&gt;">boom &amp; &lt;bang&gt;</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, junit.String())
+}
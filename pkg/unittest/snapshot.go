@@ -0,0 +1,126 @@
+package unittest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// snapshotDir is the directory, next to the suite, holding the snapshots of its tests.
+const snapshotDir = "__snapshot__"
+
+// snapshotStore holds the snapshots of a suite: the expected output of each template by test name.
+type snapshotStore struct {
+	path      string
+	suiteDir  string
+	snapshots map[string]map[string]string
+	update    bool
+	changed   bool
+}
+
+// loadSnapshots reads the snapshot file of suite, if it exists.
+func loadSnapshots(suite *Suite, update bool) (*snapshotStore, error) {
+	name := strings.TrimSuffix(filepath.Base(suite.Path), filepath.Ext(suite.Path)) + ".snap"
+	store := &snapshotStore{
+		path:      filepath.Join(suite.Dir(), snapshotDir, name),
+		suiteDir:  suite.Dir(),
+		snapshots: make(map[string]map[string]string),
+		update:    update,
+	}
+	content, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(content, &store.snapshots); err != nil {
+		return nil, fmt.Errorf("unable to parse snapshots %s: %w", store.path, err)
+	}
+	if store.snapshots == nil {
+		store.snapshots = make(map[string]map[string]string)
+	}
+	return store, nil
+}
+
+// check compares the output to the snapshot of the template in the test, or records it when updating.
+func (s *snapshotStore) check(test string, out Rendered) error {
+	want, ok := s.snapshots[test][out.Template]
+	if ok && want == string(out.Output) {
+		return nil
+	}
+	if s.update {
+		if s.snapshots[test] == nil {
+			s.snapshots[test] = make(map[string]string)
+		}
+		s.snapshots[test][out.Template] = string(out.Output)
+		s.changed = true
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("no snapshot of %s in %s, run with --update-snapshots to create it", out.Template, s.path)
+	}
+	return fmt.Errorf("%s differs from its snapshot: %s", out.Template, firstDifference(want, string(out.Output)))
+}
+
+// checkFile compares the output to the content of file, relative to the suite, or writes it when updating.
+func (s *snapshotStore) checkFile(file string, out Rendered) error {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.suiteDir, file)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil && string(want) == string(out.Output) {
+		return nil
+	}
+	if s.update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, out.Output, 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot file %s does not exist, run with --update-snapshots to create it", file)
+	}
+	return fmt.Errorf("%s differs from %s: %s", out.Template, file, firstDifference(string(want), string(out.Output)))
+}
+
+// save writes the snapshot file if snapshots were updated.
+func (s *snapshotStore) save() error {
+	if !s.changed {
+		return nil
+	}
+	content, err := yaml.MarshalWithOptions(s.snapshots, yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, content, 0o644)
+}
+
+// firstDifference describes the first line that differs between want and got.
+func firstDifference(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		switch {
+		case i >= len(wantLines):
+			return fmt.Sprintf("line %d is unexpected: %q", i+1, gotLines[i])
+		case i >= len(gotLines):
+			return fmt.Sprintf("line %d is missing: %q", i+1, wantLines[i])
+		case wantLines[i] != gotLines[i]:
+			return fmt.Sprintf("line %d is %q, expected %q", i+1, gotLines[i], wantLines[i])
+		}
+	}
+	return "contents differ"
+}
@@ -0,0 +1,190 @@
+// Package unittest runs test suites of templates: YAML files declaring the data and templates to render and
+// assertions on the rendered output, checked against snapshots, JSONPath queries and errors.
+package unittest
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/theory/jsonpath"
+)
+
+// Suite is a test suite file. Paths in it are relative to the file.
+type Suite struct {
+	Name string `yaml:"suite"` // defaults to the file name
+	// Data, Set, SetString and SetJSON are the --data, --set, --set-string and --set-json arguments of every test
+	Data            []string `yaml:"data"`
+	Set             []string `yaml:"set"`
+	SetString       []string `yaml:"set-string"`
+	SetJSON         []string `yaml:"set-json"`
+	CommonTemplates []string `yaml:"common-templates"`
+	Templates       []string `yaml:"templates"` // rendered by tests that don't list their own
+	Helm            bool     `yaml:"helm"`
+	Strict          bool     `yaml:"strict"`
+	DropExtension   *string  `yaml:"drop-extension"` // defaults to tmpl, as with --drop-extension
+	Tests           []*Test  `yaml:"tests"`
+
+	Path string `yaml:"-"` // path of the suite file
+}
+
+// Test is a test case of a suite. Its data and --set arguments are applied after the suite's.
+type Test struct {
+	Name      string      `yaml:"name"`
+	Data      []string    `yaml:"data"`
+	Set       []string    `yaml:"set"`
+	SetString []string    `yaml:"set-string"`
+	SetJSON   []string    `yaml:"set-json"`
+	Templates []string    `yaml:"templates"`
+	Asserts   []Assertion `yaml:"asserts"`
+}
+
+// Assertion checks the rendered templates of a test. Exactly one of its fields is set.
+type Assertion struct {
+	Snapshot      *SnapshotAssertion `yaml:"snapshot"`
+	Equal         *EqualAssertion    `yaml:"equal"`
+	MatchRegex    *RegexAssertion    `yaml:"match-regex"`
+	Exists        *PathAssertion     `yaml:"exists"`
+	NotExists     *PathAssertion     `yaml:"not-exists"`
+	ErrorContains *string            `yaml:"error-contains"` // rendering fails with an error containing it
+}
+
+// SnapshotAssertion checks that the output of templates is unchanged. Snapshots are kept in
+// __snapshot__/<suite file name>.snap next to the suite, or in File.
+type SnapshotAssertion struct {
+	Template string `yaml:"template"` // the rendered template to check, every template if empty
+	File     string `yaml:"file"`     // a file holding the expected output of the template
+}
+
+// PathAssertion selects values in a YAML document of the output of templates with a JSONPath query.
+type PathAssertion struct {
+	Template string `yaml:"template"` // the rendered template to check, every template if empty
+	Document int    `yaml:"document"` // the index of the YAML document in the output, from 0
+	Path     string `yaml:"path"`     // JSONPath query, the leading $ is optional
+
+	query *jsonpath.Path
+}
+
+// EqualAssertion checks that the value selected by the path equals Value, or that the values do if the path
+// selects more than one.
+type EqualAssertion struct {
+	PathAssertion `yaml:",inline"`
+	Value         any `yaml:"value"`
+}
+
+// RegexAssertion checks that every value selected by the path matches Pattern.
+type RegexAssertion struct {
+	PathAssertion `yaml:",inline"`
+	Pattern       string `yaml:"pattern"`
+
+	regex *regexp.Regexp
+}
+
+// ParseSuite decodes the suite file at path with the given content and checks it.
+func ParseSuite(path string, content []byte) (*Suite, error) {
+	suite := &Suite{}
+	if err := yaml.UnmarshalWithOptions(content, suite, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("unable to parse test suite %s: %w", path, err)
+	}
+	suite.Path = path
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := suite.check(); err != nil {
+		return nil, fmt.Errorf("invalid test suite %s: %w", path, err)
+	}
+	return suite, nil
+}
+
+// Dir returns the directory of the suite file, which its paths are relative to.
+func (s *Suite) Dir() string {
+	return filepath.Dir(s.Path)
+}
+
+// TemplatesOf returns the templates rendered by the test.
+func (s *Suite) TemplatesOf(test *Test) []string {
+	if len(test.Templates) > 0 {
+		return test.Templates
+	}
+	return s.Templates
+}
+
+func (s *Suite) check() error {
+	if len(s.Tests) == 0 {
+		return errors.New("no tests")
+	}
+	names := make(map[string]bool, len(s.Tests))
+	for i, test := range s.Tests {
+		if test == nil || test.Name == "" {
+			return fmt.Errorf("test %d has no name", i+1)
+		}
+		if names[test.Name] {
+			return fmt.Errorf("test %q is declared more than once", test.Name)
+		}
+		names[test.Name] = true
+		if len(s.TemplatesOf(test)) == 0 {
+			return fmt.Errorf("test %q has no templates", test.Name)
+		}
+		if len(test.Asserts) == 0 {
+			return fmt.Errorf("test %q has no asserts", test.Name)
+		}
+		for j := range test.Asserts {
+			if err := test.Asserts[j].check(); err != nil {
+				return fmt.Errorf("assert %d of test %q: %w", j+1, test.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *Assertion) check() error {
+	set := 0
+	for _, isSet := range []bool{a.Snapshot != nil, a.Equal != nil, a.MatchRegex != nil, a.Exists != nil, a.NotExists != nil, a.ErrorContains != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("must be exactly one of snapshot, equal, match-regex, exists, not-exists or error-contains")
+	}
+	var err error
+	switch {
+	case a.Equal != nil:
+		err = a.Equal.compile()
+	case a.MatchRegex != nil:
+		if err = a.MatchRegex.compile(); err == nil {
+			a.MatchRegex.regex, err = regexp.Compile(a.MatchRegex.Pattern)
+		}
+	case a.Exists != nil:
+		err = a.Exists.compile()
+	case a.NotExists != nil:
+		err = a.NotExists.compile()
+	}
+	return err
+}
+
+// compile parses the path, as with --data jsonpath=, adding the leading $ if it is missing.
+func (p *PathAssertion) compile() error {
+	path := p.Path
+	switch {
+	case path == "":
+		return errors.New("path is required")
+	case strings.HasPrefix(path, "$"):
+	case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+		path = "$" + path
+	default:
+		path = "$." + path
+	}
+	query, err := jsonpath.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid path %q: %w", p.Path, err)
+	}
+	if p.Document < 0 {
+		return fmt.Errorf("invalid document %d", p.Document)
+	}
+	p.query = query
+	return nil
+}
@@ -0,0 +1,99 @@
+package unittest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSuite(t *testing.T) {
+	suite, err := ParseSuite("tests/app.yaml", []byte(`
+data: [../values.yaml]
+templates: [../src]
+tests:
+  - name: renders
+    set: [.replicas=2]
+    asserts:
+      - snapshot: {}
+      - equal:
+          template: deployment.yaml
+          path: spec.replicas
+          value: 2
+  - name: own templates
+    templates: [../other.tmpl]
+    asserts:
+      - error-contains: boom
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "app", suite.Name)
+	assert.Equal(t, "tests", suite.Dir())
+	assert.Equal(t, []string{"../src"}, suite.TemplatesOf(suite.Tests[0]))
+	assert.Equal(t, []string{"../other.tmpl"}, suite.TemplatesOf(suite.Tests[1]))
+	equal := suite.Tests[0].Asserts[1].Equal
+	require.NotNil(t, equal)
+	assert.Equal(t, "deployment.yaml", equal.Template)
+	assert.Equal(t, `$["spec"]["replicas"]`, equal.query.String())
+	assert.EqualValues(t, 2, equal.Value)
+}
+
+func TestParseSuite_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			content: "templates: [a]\ntests:\n  - name: a\n    assert: []\n",
+			wantErr: "unable to parse test suite",
+		},
+		{
+			name:    "no tests",
+			content: "templates: [a]\n",
+			wantErr: "no tests",
+		},
+		{
+			name:    "duplicate names",
+			content: "templates: [a]\ntests:\n  - {name: a, asserts: [{exists: {path: a}}]}\n  - {name: a, asserts: [{exists: {path: a}}]}\n",
+			wantErr: `test "a" is declared more than once`,
+		},
+		{
+			name:    "no templates",
+			content: "tests:\n  - {name: a, asserts: [{exists: {path: a}}]}\n",
+			wantErr: `test "a" has no templates`,
+		},
+		{
+			name:    "no asserts",
+			content: "templates: [a]\ntests:\n  - {name: a}\n",
+			wantErr: `test "a" has no asserts`,
+		},
+		{
+			name:    "two kinds in an assert",
+			content: "templates: [a]\ntests:\n  - {name: a, asserts: [{exists: {path: a}, error-contains: b}]}\n",
+			wantErr: "assert 1 of test \"a\": must be exactly one of",
+		},
+		{
+			name:    "missing path",
+			content: "templates: [a]\ntests:\n  - {name: a, asserts: [{equal: {value: 1}}]}\n",
+			wantErr: "path is required",
+		},
+		{
+			name:    "invalid path",
+			content: "templates: [a]\ntests:\n  - {name: a, asserts: [{exists: {path: \"a[\"}}]}\n",
+			wantErr: "invalid path",
+		},
+		{
+			name:    "invalid pattern",
+			content: "templates: [a]\ntests:\n  - {name: a, asserts: [{match-regex: {path: a, pattern: \"(\"}}]}\n",
+			wantErr: "error parsing regexp",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSuite("suite.yaml", []byte(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+#!/bin/sh
+# Regenerates the sops fixtures of this directory with the sops binary, from the plaintext below. The fixtures
+# were generated with sops 3.13.3, the version recorded in their metadata.
+#
+#   PATH=/path/to/sops/dir:$PATH ./generate.sh
+set -eu
+cd "$(dirname "$0")"
+
+AGE_RECIPIENT=$(sed -n 's/^# public key: //p' age-test-key.txt)
+PGP_FINGERPRINT=F286CC328F856412855EF672804717DFA3482783
+GNUPGHOME=$(mktemp -d)
+export GNUPGHOME
+trap 'rm -rf "$GNUPGHOME"' EXIT
+gpg --batch --quiet --import pgp-test-key.asc
+
+encrypt() { # <name> <plaintext> [sops flags...]
+	name=$1 plaintext=$2
+	shift 2
+	ext=${name##*.}
+	printf '%s' "$plaintext" >"$GNUPGHOME/plain.$ext"
+	sops encrypt "$@" --input-type "$ext" --output-type "$ext" "$GNUPGHOME/plain.$ext" >"$name"
+}
+
+encrypt secrets.sops.yaml '# database settings
+database:
+  host: db.internal # the primary
+  password: hunter2
+  port: 5432
+api_keys:
+  # one entry per provider
+  - name: payments
+    token: tok-123
+matrix:
+  - [a, b]
+  - - name: nested
+      password: deep-secret
+enabled: true
+ratio: 0.5
+' --age "$AGE_RECIPIENT" --encrypted-regex '^(password|token)$'
+
+encrypt secrets.sops.json '{
+  "db": {"user": "admin", "password": "s3cret"},
+  "retries": 3,
+  "hosts": [["a", "b"], [{"port": 5432}]]
+}
+' --age "$AGE_RECIPIENT"
+
+encrypt pgp.sops.yaml 'smtp:
+  password: pgp-pass
+' --pgp "$PGP_FINGERPRINT"
+
+# mac_only_encrypted can only be set in a creation rule.
+cat >"$GNUPGHOME/mac-only.yaml" <<RULES
+creation_rules:
+  - age: $AGE_RECIPIENT
+    encrypted_suffix: _secret
+    mac_only_encrypted: true
+RULES
+SOPS_CONFIG="$GNUPGHOME/mac-only.yaml" encrypt mac-only.sops.yaml 'plain: visible
+api:
+  db_secret: abc
+  count: 7
+'